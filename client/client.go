@@ -41,6 +41,10 @@ const (
 	// QueryTypeOpenSessions is the build in query type for Client.QueryWorkflow() call. Use this query type to get all open
 	// sessions in the workflow. The result will be a list of SessionInfo encoded in the encoded.Value.
 	QueryTypeOpenSessions string = internal.QueryTypeOpenSessions
+
+	// QueryTypePendingOperations is the build in query type for Client.QueryWorkflow() call. Use this query type to get
+	// the operations the workflow is waiting on. The result will be a PendingOperations encoded in the encoded.Value.
+	QueryTypePendingOperations string = internal.QueryTypePendingOperations
 )
 
 type (
@@ -65,9 +69,55 @@ type (
 	// QueryWorkflowWithOptionsResponse defines the response to QueryWorkflowWithOptions
 	QueryWorkflowWithOptionsResponse = internal.QueryWorkflowWithOptionsResponse
 
+	// UpdateWorkflowWithOptionsRequest defines the request to UpdateWorkflowWithOptions
+	UpdateWorkflowWithOptionsRequest = internal.UpdateWorkflowWithOptionsRequest
+
+	// ResetWorkflowRequest defines the request to ResetWorkflow
+	ResetWorkflowRequest = internal.ResetWorkflowRequest
+
+	// ResetWorkflowsRequest defines the request to ResetWorkflows
+	ResetWorkflowsRequest = internal.ResetWorkflowsRequest
+
+	// ResetPoint selects the decision a workflow execution is reset to.
+	ResetPoint = internal.ResetPoint
+
+	// BatchWorkflowRequest defines the request to BatchWorkflow
+	BatchWorkflowRequest = internal.BatchWorkflowRequest
+
+	// BatchWorkflowResponse defines the response to BatchWorkflow
+	BatchWorkflowResponse = internal.BatchWorkflowResponse
+
+	// BatchWorkflowProgress reports the progress of BatchWorkflow.
+	BatchWorkflowProgress = internal.BatchWorkflowProgress
+
+	// BatchWorkflowFailure describes an execution a batch operation failed for.
+	BatchWorkflowFailure = internal.BatchWorkflowFailure
+
+	// BatchOperation is the operation BatchWorkflow applies to every matching workflow execution.
+	BatchOperation = internal.BatchOperation
+
+	// UpdateRejectedError is returned from UpdateWorkflow when the workflow rejected the update.
+	UpdateRejectedError = internal.UpdateRejectedError
+
 	// ParentClosePolicy defines the behavior performed on a child workflow when its parent is closed
 	ParentClosePolicy = internal.ParentClosePolicy
 
+	// PendingOperations is the result of the QueryTypePendingOperations query.
+	PendingOperations = internal.PendingOperations
+
+	// PendingActivityInfo describes an activity that is not completed yet.
+	PendingActivityInfo = internal.PendingActivityInfo
+
+	// PendingTimerInfo describes a timer that has not fired yet.
+	PendingTimerInfo = internal.PendingTimerInfo
+
+	// PendingChildWorkflowInfo describes a child workflow that is not closed yet.
+	PendingChildWorkflowInfo = internal.PendingChildWorkflowInfo
+
+	// PendingExternalWorkflowInfo describes a signal or a cancellation request sent to another workflow that is
+	// not acknowledged yet.
+	PendingExternalWorkflowInfo = internal.PendingExternalWorkflowInfo
+
 	// Client is the client for starting and getting information about a workflow executions as well as
 	// completing activities asynchronously.
 	Client interface {
@@ -166,6 +216,34 @@ type (
 		//	- InternalServiceError
 		TerminateWorkflow(ctx context.Context, workflowID string, runID string, reason string, details []byte) error
 
+		// ResetWorkflow resets a workflow execution to an earlier decision and returns the new run. The current run
+		// is terminated and the new run replays the history up to the reset point selected by request.ResetPoint,
+		// see ResetPointFirstDecisionCompleted, ResetPointLastDecisionCompleted, ResetPointBadBinary and ResetPointEventID.
+		// The errors it can return:
+		//	- EntityNotExistsError
+		//	- BadRequestError
+		//	- InternalServiceError
+		ResetWorkflow(ctx context.Context, request *ResetWorkflowRequest) (*workflow.Execution, error)
+
+		// ResetWorkflows resets every workflow execution matching the visibility query request.Query to
+		// request.ResetPoint. It is BatchWorkflow with a BatchReset operation: executions which fail to reset are
+		// reported in BatchWorkflowResponse.Failures and an interrupted call can be resumed from its Checkpoint.
+		// This API only works with ElasticSearch.
+		// The errors it can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		ResetWorkflows(ctx context.Context, request *ResetWorkflowsRequest) (*BatchWorkflowResponse, error)
+
+		// BatchWorkflow applies request.Operation to every workflow execution matching the visibility query
+		// request.Query with bounded concurrency and rate, see BatchCancel, BatchTerminate, BatchSignal and BatchReset.
+		// Per-execution failures are reported in BatchWorkflowResponse.Failures and through request.ProgressHandler.
+		// If an error is returned, BatchWorkflowResponse.Checkpoint can be used to resume the batch.
+		// This API only works with ElasticSearch.
+		// The errors it can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		BatchWorkflow(ctx context.Context, request *BatchWorkflowRequest) (*BatchWorkflowResponse, error)
+
 		// GetWorkflowHistory gets history events of a particular workflow
 		// - workflow ID of the workflow.
 		// - runID can be default(empty string). if empty string then it will pick the last running execution of that workflow ID.
@@ -326,6 +404,36 @@ type (
 		//  - QueryFailError
 		QueryWorkflowWithOptions(ctx context.Context, request *QueryWorkflowWithOptionsRequest) (*QueryWorkflowWithOptionsResponse, error)
 
+		// UpdateWorkflow sends an update to a given workflow execution and waits for the update handler to produce
+		// the result. Parameter workflowID and updateName are required, other parameters are optional. Unlike
+		// SignalWorkflow, the caller observes the outcome of the update, unlike QueryWorkflow, the handler may
+		// mutate the workflow state.
+		// See comments at workflow.SetUpdateHandler(ctx Context, updateName string, handler interface{}, options UpdateHandlerOptions)
+		// for more details on how to setup update handler within the target workflow.
+		// - workflowID is required.
+		// - runID can be default(empty string). if empty string then it will pick the running execution of that workflow ID.
+		// - updateName is the name of the update.
+		// - args... are the optional update parameters.
+		// The errors it can return:
+		//  - UpdateRejectedError
+		//  - the error returned by the update handler
+		//  - BadRequestError
+		//  - InternalServiceError
+		//  - EntityNotExistError
+		//  - QueryFailError
+		UpdateWorkflow(ctx context.Context, workflowID string, runID string, updateName string, args ...interface{}) (encoded.Value, error)
+
+		// UpdateWorkflowWithOptions sends an update to a given workflow execution and waits for the update handler
+		// to produce the result. See UpdateWorkflowWithOptionsRequest for more information.
+		// The errors it can return:
+		//  - UpdateRejectedError
+		//  - the error returned by the update handler
+		//  - BadRequestError
+		//  - InternalServiceError
+		//  - EntityNotExistError
+		//  - QueryFailError
+		UpdateWorkflowWithOptions(ctx context.Context, request *UpdateWorkflowWithOptionsRequest) (encoded.Value, error)
+
 		// DescribeWorkflowExecution returns information about the specified workflow execution.
 		// - runID can be default(empty string). if empty string then it will pick the last running execution of that workflow ID.
 		//
@@ -410,6 +518,47 @@ func NewDomainClient(service workflowserviceclient.Interface, options *Options)
 	return internal.NewDomainClient(service, options)
 }
 
+// ResetPointFirstDecisionCompleted resets the run to its first completed decision.
+func ResetPointFirstDecisionCompleted() ResetPoint {
+	return internal.ResetPointFirstDecisionCompleted()
+}
+
+// ResetPointLastDecisionCompleted resets the run to its last completed decision.
+func ResetPointLastDecisionCompleted() ResetPoint {
+	return internal.ResetPointLastDecisionCompleted()
+}
+
+// ResetPointBadBinary resets the run to right before the first decision completed by the worker binary with the
+// given checksum.
+func ResetPointBadBinary(binaryChecksum string) ResetPoint {
+	return internal.ResetPointBadBinary(binaryChecksum)
+}
+
+// ResetPointEventID resets the run to the given decision finish event.
+func ResetPointEventID(eventID int64) ResetPoint {
+	return internal.ResetPointEventID(eventID)
+}
+
+// BatchCancel requests cancellation of every workflow execution matching the batch query.
+func BatchCancel() BatchOperation {
+	return internal.BatchCancel()
+}
+
+// BatchTerminate terminates every workflow execution matching the batch query.
+func BatchTerminate(reason string, details []byte) BatchOperation {
+	return internal.BatchTerminate(reason, details)
+}
+
+// BatchSignal sends the signal to every workflow execution matching the batch query.
+func BatchSignal(signalName string, arg interface{}) BatchOperation {
+	return internal.BatchSignal(signalName, arg)
+}
+
+// BatchReset resets every workflow execution matching the batch query to the given reset point.
+func BatchReset(reason string, resetPoint ResetPoint) BatchOperation {
+	return internal.BatchReset(reason, resetPoint)
+}
+
 // make sure if new methods are added to internal.Client they are also added to public Client.
 var _ Client = internal.Client(nil)
 var _ internal.Client = Client(nil)
@@ -42,9 +42,113 @@ type (
 	// Cadence support using different DataConverters for different activity/childWorkflow in same workflow.
 	//   2. Activity/Workflow worker that run these activity/childWorkflow, through worker.Options.
 	DataConverter = internal.DataConverter
+
+	// PayloadCodec is a byte level transformation applied on top of a DataConverter, for example to
+	// encrypt or compress the serialized payloads. Decode must reverse Encode.
+	PayloadCodec = internal.PayloadCodec
+
+	// DefaultDataConverterOptions configures the DataConverter returned by NewDefaultDataConverter.
+	DefaultDataConverterOptions = internal.DefaultDataConverterOptions
+
+	// PayloadEncoding encodes single values into the payloads written by the composite DataConverter.
+	// The name and version of the encoding are recorded next to every value, so payloads stay readable
+	// as long as an encoding with the same name and version is registered.
+	PayloadEncoding = internal.PayloadEncoding
+
+	// CompositeDataConverterOptions configures the DataConverter returned by NewCompositeDataConverter.
+	CompositeDataConverterOptions = internal.CompositeDataConverterOptions
+
+	// BlobStore stores the payloads offloaded by the blob store codec.
+	// Implementations must be safe for concurrent use and must keep the blobs for as long as any workflow
+	// history referencing them can be replayed.
+	BlobStore = internal.BlobStore
+
+	// BlobStoreCodecOptions configures the codec returned by NewBlobStoreCodec.
+	BlobStoreCodecOptions = internal.BlobStoreCodecOptions
+
+	// AESGCMCodecOptions configures the codec returned by NewAESGCMCodec.
+	AESGCMCodecOptions = internal.AESGCMCodecOptions
 )
 
 // GetDefaultDataConverter return default data converter used by Cadence worker
 func GetDefaultDataConverter() DataConverter {
 	return internal.DefaultDataConverter
 }
+
+// NewDefaultDataConverter creates the default DataConverter with the given options.
+// It encodes thrift structures with thrift, protobuf messages generated by google.golang.org/protobuf with
+// protobuf and everything else with json.
+func NewDefaultDataConverter(options DefaultDataConverterOptions) DataConverter {
+	return internal.NewDefaultDataConverter(options)
+}
+
+// NewCompositeDataConverter creates a DataConverter writing every value in an envelope which records the name
+// and version of the encoding used for it. Reading decodes each value with the registered encoding of the same
+// name and version, and payloads without an envelope with the legacy DataConverter. This allows to migrate
+// encodings gradually: register the new encoding in front of the old one and keep the old one until no open
+// workflow references its payloads.
+func NewCompositeDataConverter(options CompositeDataConverterOptions) DataConverter {
+	return internal.NewCompositeDataConverter(options)
+}
+
+// NewJSONPayloadEncoding creates the json PayloadEncoding, it supports any value.
+func NewJSONPayloadEncoding() PayloadEncoding {
+	return internal.NewJSONPayloadEncoding()
+}
+
+// NewThriftPayloadEncoding creates the PayloadEncoding for thrift structures.
+func NewThriftPayloadEncoding() PayloadEncoding {
+	return internal.NewThriftPayloadEncoding()
+}
+
+// NewProtoPayloadEncoding creates the PayloadEncoding for protobuf messages generated by google.golang.org/protobuf,
+// writing them in binary or json form. Values in either form are decoded.
+func NewProtoPayloadEncoding(json bool) PayloadEncoding {
+	return internal.NewProtoPayloadEncoding(json)
+}
+
+// NewBytesPayloadEncoding creates the PayloadEncoding that writes byte slices as they are.
+func NewBytesPayloadEncoding() PayloadEncoding {
+	return internal.NewBytesPayloadEncoding()
+}
+
+// NewCodecDataConverter creates a DataConverter that encodes the payloads produced by dataConverter with the given
+// codecs. When encoding the codecs are applied in the given order and when decoding in the reverse order, so
+//  NewCodecDataConverter(dc, NewGzipCodec(1024), aesCodec)
+// compresses payloads before encrypting them. Empty payloads are passed through unchanged.
+// The returned DataConverter has to be configured everywhere the payloads are produced or consumed: on the client,
+// on the workers and through workflow.WithDataConverter inside workflows, exactly as any other DataConverter.
+func NewCodecDataConverter(dataConverter DataConverter, codecs ...PayloadCodec) DataConverter {
+	return internal.NewCodecDataConverter(dataConverter, codecs...)
+}
+
+// NewAESGCMCodec creates a PayloadCodec that encrypts payloads with AES in Galois/Counter Mode.
+// Every payload is encrypted with a fresh random nonce and carries the ID of the key used to encrypt it,
+// so keys can be rotated without breaking the payloads encrypted with the previous ones.
+func NewAESGCMCodec(options AESGCMCodecOptions) (PayloadCodec, error) {
+	return internal.NewAESGCMCodec(options)
+}
+
+// NewGzipCodec creates a PayloadCodec that compresses payloads of at least minSize bytes with gzip.
+func NewGzipCodec(minSize int) PayloadCodec {
+	return internal.NewGzipCodec(minSize)
+}
+
+// NewBlobStoreCodec creates a PayloadCodec that stores payloads above the threshold in the BlobStore and replaces
+// them with a reference, so only the reference is recorded in the workflow history. References are resolved when
+// decoding, including during replay.
+func NewBlobStoreCodec(options BlobStoreCodecOptions) (PayloadCodec, error) {
+	return internal.NewBlobStoreCodec(options)
+}
+
+// NewBlobStoreDataConverter creates a DataConverter that offloads the payloads produced by dataConverter to the
+// BlobStore when they are above the threshold. See NewBlobStoreCodec.
+func NewBlobStoreDataConverter(dataConverter DataConverter, options BlobStoreCodecOptions) (DataConverter, error) {
+	return internal.NewBlobStoreDataConverter(dataConverter, options)
+}
+
+// NewFileBlobStore creates a BlobStore keeping every blob in a file of the directory. It is meant for tests and
+// local development, production deployments need a store shared by all the workers.
+func NewFileBlobStore(dir string) (BlobStore, error) {
+	return internal.NewFileBlobStore(dir)
+}
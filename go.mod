@@ -1,56 +1,87 @@
 module go.uber.org/cadence
 
-go 1.13
+go 1.25.0
 
 require (
-	github.com/anmitsu/go-shlex v0.0.0-20161002113705-648efa622239 // indirect
 	github.com/apache/thrift v0.0.0-20161221203622-b2a4d4ae21c7
+	github.com/facebookgo/clock v0.0.0-20150410010913-600d898af40a
+	github.com/golang/mock v1.1.1
+	github.com/opentracing/opentracing-go v1.1.0
+	github.com/pborman/uuid v0.0.0-20160209185913-a97ce2ca70fa
+	github.com/robfig/cron v1.2.0
+	github.com/sirupsen/logrus v0.11.5
+	github.com/stretchr/testify v1.3.0
+	github.com/uber-go/tally v3.3.1+incompatible
+	github.com/uber/jaeger-client-go v2.22.1+incompatible
+	github.com/uber/tchannel-go v1.14.0
+	go.uber.org/atomic v1.5.1
+	go.uber.org/goleak v0.10.0
+	go.uber.org/thriftrw v1.20.2
+	go.uber.org/yarpc v1.42.0
+	go.uber.org/zap v1.8.0
+	golang.org/x/net v0.56.0
+	golang.org/x/time v0.0.0-20170927054726-6dc17368e09b
+	golang.org/x/tools v0.47.0
+	google.golang.org/protobuf v1.27.1
+)
+
+require (
+	cloud.google.com/go v0.26.0 // indirect
+	github.com/BurntSushi/toml v0.3.1 // indirect
+	github.com/anmitsu/go-shlex v0.0.0-20161002113705-648efa622239 // indirect
 	github.com/beorn7/perks v0.0.0-20180321164747-3a771d992973 // indirect
 	github.com/bmizerany/perks v0.0.0-20141205001514-d9a9656a3a4b // indirect
+	github.com/client9/misspell v0.3.4 // indirect
 	github.com/codahale/hdrhistogram v0.0.0-20161010025455-3a0bb77429bd // indirect
 	github.com/crossdock/crossdock-go v0.0.0-20160816171116-049aabb0122b // indirect
-	github.com/facebookgo/clock v0.0.0-20150410010913-600d898af40a
+	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/fatih/structtag v1.0.0 // indirect
 	github.com/flynn/go-shlex v0.0.0-20150515145356-3f9db97f8568 // indirect
 	github.com/gogo/googleapis v1.3.1 // indirect
+	github.com/gogo/protobuf v1.3.0 // indirect
 	github.com/gogo/status v1.1.0 // indirect
-	github.com/golang/mock v1.1.1
+	github.com/golang/glog v0.0.0-20160126235308-23def4e6c14b // indirect
+	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/google/go-cmp v0.6.0 // indirect
 	github.com/jessevdk/go-flags v1.4.0 // indirect
+	github.com/kisielk/errcheck v1.2.0 // indirect
+	github.com/kisielk/gotool v1.0.0 // indirect
 	github.com/kr/pretty v0.1.0 // indirect
+	github.com/kr/pty v1.1.1 // indirect
+	github.com/kr/text v0.1.0 // indirect
 	github.com/matttproud/golang_protobuf_extensions v1.0.1 // indirect
-	github.com/opentracing/opentracing-go v1.1.0
-	github.com/pborman/uuid v0.0.0-20160209185913-a97ce2ca70fa
 	github.com/pkg/errors v0.8.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/prashantv/protectmem v0.0.0-20171002184600-e20412882b3a // indirect
 	github.com/prometheus/client_golang v0.8.0 // indirect
 	github.com/prometheus/client_model v0.0.0-20171117100541-99fa1f4be8e5 // indirect
 	github.com/prometheus/common v0.0.0-20170908161822-2f17f4a9d485 // indirect
 	github.com/prometheus/procfs v0.0.0-20180321230812-780932d4fbbe // indirect
-	github.com/robfig/cron v1.2.0
 	github.com/samuel/go-thrift v0.0.0-20190219015601-e8b6b52668fe // indirect
-	github.com/sirupsen/logrus v0.11.5
 	github.com/streadway/quantile v0.0.0-20150917103942-b0c588724d25 // indirect
 	github.com/stretchr/objx v0.1.1 // indirect
-	github.com/stretchr/testify v1.3.0
 	github.com/uber-go/mapdecode v1.0.0 // indirect
-	github.com/uber-go/tally v3.3.1+incompatible
-	github.com/uber/jaeger-client-go v2.22.1+incompatible
 	github.com/uber/jaeger-lib v2.2.0+incompatible // indirect
-	github.com/uber/tchannel-go v1.14.0
-	go.uber.org/atomic v1.5.1
+	github.com/yuin/goldmark v1.4.13 // indirect
 	go.uber.org/dig v1.7.0 // indirect
 	go.uber.org/fx v1.9.0 // indirect
-	go.uber.org/goleak v0.10.0
 	go.uber.org/multierr v1.1.0 // indirect
 	go.uber.org/net/metrics v1.0.1 // indirect
-	go.uber.org/thriftrw v1.20.2
 	go.uber.org/tools v0.0.0-20190430173459-422a61c266e1 // indirect
-	go.uber.org/yarpc v1.42.0
-	go.uber.org/zap v1.8.0
+	golang.org/x/crypto v0.53.0 // indirect
 	golang.org/x/lint v0.0.0-20191125180803-fdd1cda4f05f // indirect
-	golang.org/x/net v0.0.0-20200114155413-6afb5195e5aa
-	golang.org/x/time v0.0.0-20170927054726-6dc17368e09b
-	golang.org/x/tools v0.0.0-20200127195909-ed30b9180dd3 // indirect
+	golang.org/x/mod v0.37.0 // indirect
+	golang.org/x/oauth2 v0.0.0-20180821212333-d2e6202438be // indirect
+	golang.org/x/sync v0.21.0 // indirect
+	golang.org/x/sys v0.46.0 // indirect
+	golang.org/x/telemetry v0.0.0-20260625142307-59b4966ccb57 // indirect
+	golang.org/x/term v0.44.0 // indirect
+	golang.org/x/text v0.38.0 // indirect
+	golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543 // indirect
+	google.golang.org/appengine v1.1.0 // indirect
+	google.golang.org/genproto v0.0.0-20180817151627-c66870c02cf8 // indirect
 	google.golang.org/grpc v1.23.1 // indirect
+	gopkg.in/check.v1 v0.0.0-20161208181325-20d25e280405 // indirect
 	gopkg.in/yaml.v2 v2.2.2 // indirect
+	honnef.co/go/tools v0.0.0-20190523083050-ea95bdfd59fc // indirect
 )
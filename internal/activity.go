@@ -62,6 +62,13 @@ type (
 		// this Name as a prefix + activity function name.
 		Name                          string
 		DisableAlreadyRegisteredCheck bool
+		// EnableAutoHeartbeat makes the worker record heartbeats for the activity at a fraction of its
+		// HeartbeatTimeout while it is running, reusing the details of the last RecordActivityHeartbeat call.
+		// The heartbeats are recorded through the ActivityInterceptor chain without details.
+		// Long running activities don't need to heartbeat on their own to avoid heartbeat timeouts, but they
+		// still have to check ctx.Done() to learn about cancellation. It has no effect when the activity is
+		// scheduled without a HeartbeatTimeout.
+		EnableAutoHeartbeat bool
 	}
 
 	// ActivityOptions stores all activity-specific parameters that will be stored inside of a context.
@@ -95,6 +102,11 @@ type (
 		// Optional: default false
 		WaitForCancellation bool
 
+		// CancellationType - What happens to the activity when the context is cancelled. It takes precedence over
+		// WaitForCancellation when set.
+		// Optional: default CancellationTypeUnspecified, which is selected by WaitForCancellation.
+		CancellationType CancellationType
+
 		// ActivityID - Business level activity ID, this is not needed for most of the cases if you have
 		// to specify this then talk to cadence team. This is something will be done in future.
 		// Optional: default empty string
@@ -184,6 +196,11 @@ func RegisterActivityWithOptions(activityFunc interface{}, opts RegisterActivity
 
 // GetActivityInfo returns information about currently executing activity.
 func GetActivityInfo(ctx context.Context) ActivityInfo {
+	i := getActivityInterceptor(ctx)
+	return i.GetActivityInfo(ctx)
+}
+
+func (a *activityEnvironmentInterceptor) GetActivityInfo(ctx context.Context) ActivityInfo {
 	env := getActivityEnv(ctx)
 	return ActivityInfo{
 		ActivityID:         env.activityID,
@@ -203,6 +220,11 @@ func GetActivityInfo(ctx context.Context) ActivityInfo {
 
 // HasHeartbeatDetails checks if there is heartbeat details from last attempt.
 func HasHeartbeatDetails(ctx context.Context) bool {
+	i := getActivityInterceptor(ctx)
+	return i.HasHeartbeatDetails(ctx)
+}
+
+func (a *activityEnvironmentInterceptor) HasHeartbeatDetails(ctx context.Context) bool {
 	env := getActivityEnv(ctx)
 	return len(env.heartbeatDetails) > 0
 }
@@ -213,6 +235,11 @@ func HasHeartbeatDetails(ctx context.Context) bool {
 // details reported by activity from the failed attempt, the details would be delivered along with the activity task for
 // retry attempt. Activity could extract the details by GetHeartbeatDetails() and resume from the progress.
 func GetHeartbeatDetails(ctx context.Context, d ...interface{}) error {
+	i := getActivityInterceptor(ctx)
+	return i.GetHeartbeatDetails(ctx, d...)
+}
+
+func (a *activityEnvironmentInterceptor) GetHeartbeatDetails(ctx context.Context, d ...interface{}) error {
 	env := getActivityEnv(ctx)
 	if len(env.heartbeatDetails) == 0 {
 		return ErrNoData
@@ -223,12 +250,22 @@ func GetHeartbeatDetails(ctx context.Context, d ...interface{}) error {
 
 // GetActivityLogger returns a logger that can be used in activity
 func GetActivityLogger(ctx context.Context) *zap.Logger {
+	i := getActivityInterceptor(ctx)
+	return i.GetLogger(ctx)
+}
+
+func (a *activityEnvironmentInterceptor) GetLogger(ctx context.Context) *zap.Logger {
 	env := getActivityEnv(ctx)
 	return env.logger
 }
 
 // GetActivityMetricsScope returns a metrics scope that can be used in activity
 func GetActivityMetricsScope(ctx context.Context) tally.Scope {
+	i := getActivityInterceptor(ctx)
+	return i.GetMetricsScope(ctx)
+}
+
+func (a *activityEnvironmentInterceptor) GetMetricsScope(ctx context.Context) tally.Scope {
 	env := getActivityEnv(ctx)
 	return env.metricsScope
 }
@@ -238,6 +275,11 @@ func GetActivityMetricsScope(ctx context.Context) tally.Scope {
 // hit, the worker will cancel the activity context and then exit. The timeout can be defined by worker option: WorkerStopTimeout.
 // Use this channel to handle activity graceful exit when the activity worker stops.
 func GetWorkerStopChannel(ctx context.Context) <-chan struct{} {
+	i := getActivityInterceptor(ctx)
+	return i.GetWorkerStopChannel(ctx)
+}
+
+func (a *activityEnvironmentInterceptor) GetWorkerStopChannel(ctx context.Context) <-chan struct{} {
 	env := getActivityEnv(ctx)
 	return env.workerStopChannel
 }
@@ -251,6 +293,11 @@ func GetWorkerStopChannel(ctx context.Context) <-chan struct{} {
 // details - the details that you provided here can be seen in the worflow when it receives TimeoutError, you
 // can check error TimeoutType()/Details().
 func RecordActivityHeartbeat(ctx context.Context, details ...interface{}) {
+	i := getActivityInterceptor(ctx)
+	i.RecordHeartbeat(ctx, details...)
+}
+
+func (a *activityEnvironmentInterceptor) RecordHeartbeat(ctx context.Context, details ...interface{}) {
 	env := getActivityEnv(ctx)
 	if env.isLocalActivity {
 		// no-op for local activity
@@ -258,16 +305,22 @@ func RecordActivityHeartbeat(ctx context.Context, details ...interface{}) {
 	}
 	var data []byte
 	var err error
-	// We would like to be a able to pass in "nil" as part of details(that is no progress to report to)
-	if len(details) != 1 || details[0] != nil {
-		data, err = encodeArgs(getDataConverterFromActivityCtx(ctx), details)
-		if err != nil {
-			panic(err)
+	if len(details) == 0 && ctx.Value(autoHeartbeatContextKey) != nil {
+		// heartbeats recorded by the worker repeat the details of the last one
+		data = env.getLastHeartbeatDetails()
+	} else {
+		// We would like to be a able to pass in "nil" as part of details(that is no progress to report to)
+		if len(details) != 1 || details[0] != nil {
+			data, err = encodeArgs(getDataConverterFromActivityCtx(ctx), details)
+			if err != nil {
+				panic(err)
+			}
 		}
+		env.setLastHeartbeatDetails(data)
 	}
 	err = env.serviceInvoker.Heartbeat(data, false)
 	if err != nil {
-		log := GetActivityLogger(ctx)
+		log := a.GetLogger(ctx)
 		log.Debug("RecordActivityHeartbeat With Error:", zap.Error(err))
 	}
 }
@@ -294,6 +347,7 @@ func WithActivityTask(
 	workerStopChannel <-chan struct{},
 	contextPropagators []ContextPropagator,
 	tracer opentracing.Tracer,
+	interceptors []ActivityInterceptorFactory,
 ) context.Context {
 	var deadline time.Time
 	scheduled := time.Unix(0, task.GetScheduledTimestamp())
@@ -336,6 +390,8 @@ func WithActivityTask(
 		dataConverter:      dataConverter,
 		attempt:            task.GetAttempt(),
 		heartbeatDetails:   task.HeartbeatDetails,
+		// until the activity reports progress the auto heartbeat keeps the details of the previous attempt
+		lastHeartbeatDetails: task.HeartbeatDetails,
 		workflowType: &WorkflowType{
 			Name: *task.WorkflowType.Name,
 		},
@@ -343,5 +399,6 @@ func WithActivityTask(
 		workerStopChannel:  workerStopChannel,
 		contextPropagators: contextPropagators,
 		tracer:             tracer,
+		interceptors:       interceptors,
 	})
 }
@@ -23,7 +23,9 @@ package internal
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
@@ -226,3 +228,114 @@ func (s *activityTestSuite) TestGetWorkerStopChannel() {
 	channel := GetWorkerStopChannel(ctx)
 	s.NotNil(channel)
 }
+
+func (s *activityTestSuite) TestActivityInterceptor() {
+	ctx, cancel := context.WithCancel(context.Background())
+	invoker := newServiceInvoker([]byte("task-token"), "identity", s.service, cancel, 1, make(chan struct{}))
+	tracer := &activityTracingInterceptorFactory{}
+	ctx = context.WithValue(ctx, activityEnvContextKey, &activityEnvironment{
+		serviceInvoker: invoker,
+		activityType:   ActivityType{Name: "greet"},
+		interceptors:   []ActivityInterceptorFactory{tracer},
+	})
+
+	s.service.EXPECT().RecordActivityTaskHeartbeat(gomock.Any(), gomock.Any(), callOptions...).
+		Return(&shared.RecordActivityTaskHeartbeatResponse{}, nil).Times(1)
+
+	ae := &activityExecutor{name: "greet", fn: func(ctx context.Context, name string) (string, error) {
+		RecordActivityHeartbeat(ctx, "progress")
+		return "Hello " + name, nil
+	}}
+	result, err := ae.ExecuteWithActualArgs(ctx, []interface{}{"Flow"})
+	s.NoError(err)
+	var greeting string
+	s.NoError(getDefaultDataConverter().FromData(result, &greeting))
+	s.Equal("Hello Flow", greeting)
+
+	s.Equal(1, len(tracer.instances))
+	s.Equal([]string{
+		"ExecuteActivity greet begin",
+		"RecordHeartbeat",
+		"ExecuteActivity greet end",
+	}, tracer.instances[0].trace)
+}
+
+func (s *activityTestSuite) TestActivityInterceptor_AutoHeartbeat() {
+	ctx, cancel := context.WithCancel(context.Background())
+	invoker := newServiceInvoker([]byte("task-token"), "identity", s.service, cancel, 1, make(chan struct{}))
+	counter := &heartbeatCountingInterceptorFactory{}
+	ctx = context.WithValue(ctx, activityEnvContextKey, &activityEnvironment{
+		serviceInvoker:       invoker,
+		activityType:         ActivityType{Name: "wait"},
+		heartbeatTimeout:     20 * time.Millisecond,
+		lastHeartbeatDetails: []byte("progress"),
+		interceptors:         []ActivityInterceptorFactory{counter},
+	})
+
+	s.service.EXPECT().RecordActivityTaskHeartbeat(gomock.Any(), gomock.Any(), callOptions...).
+		Do(func(_ interface{}, request *shared.RecordActivityTaskHeartbeatRequest, _ ...interface{}) {
+			s.Equal([]byte("progress"), request.Details)
+		}).Return(&shared.RecordActivityTaskHeartbeatResponse{}, nil).MinTimes(1)
+
+	ae := &activityExecutor{name: "wait", autoHeartbeat: true, fn: func(ctx context.Context) error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	}}
+	_, err := ae.ExecuteWithActualArgs(ctx, nil)
+	s.NoError(err)
+	invoker.Close(false)
+	s.True(atomic.LoadInt32(&counter.heartbeats) > 1)
+}
+
+var _ ActivityInterceptorFactory = (*heartbeatCountingInterceptorFactory)(nil)
+
+type heartbeatCountingInterceptorFactory struct {
+	heartbeats int32
+}
+
+func (f *heartbeatCountingInterceptorFactory) NewInterceptor(info *ActivityInfo, next ActivityInterceptor) ActivityInterceptor {
+	return &heartbeatCountingInterceptor{ActivityInterceptorBase: ActivityInterceptorBase{Next: next}, factory: f}
+}
+
+type heartbeatCountingInterceptor struct {
+	ActivityInterceptorBase
+	factory *heartbeatCountingInterceptorFactory
+}
+
+func (t *heartbeatCountingInterceptor) RecordHeartbeat(ctx context.Context, details ...interface{}) {
+	atomic.AddInt32(&t.factory.heartbeats, 1)
+	t.Next.RecordHeartbeat(ctx, details...)
+}
+
+var _ ActivityInterceptorFactory = (*activityTracingInterceptorFactory)(nil)
+
+type activityTracingInterceptorFactory struct {
+	instances []*activityTracingInterceptor
+}
+
+func (t *activityTracingInterceptorFactory) NewInterceptor(info *ActivityInfo, next ActivityInterceptor) ActivityInterceptor {
+	result := &activityTracingInterceptor{
+		ActivityInterceptorBase: ActivityInterceptorBase{Next: next},
+	}
+	t.instances = append(t.instances, result)
+	return result
+}
+
+var _ ActivityInterceptor = (*activityTracingInterceptor)(nil)
+
+type activityTracingInterceptor struct {
+	ActivityInterceptorBase
+	trace []string
+}
+
+func (t *activityTracingInterceptor) ExecuteActivity(ctx context.Context, activityType string, args ...interface{}) []interface{} {
+	t.trace = append(t.trace, "ExecuteActivity "+activityType+" begin")
+	result := t.Next.ExecuteActivity(ctx, activityType, args...)
+	t.trace = append(t.trace, "ExecuteActivity "+activityType+" end")
+	return result
+}
+
+func (t *activityTracingInterceptor) RecordHeartbeat(ctx context.Context, details ...interface{}) {
+	t.trace = append(t.trace, "RecordHeartbeat")
+	t.Next.RecordHeartbeat(ctx, details...)
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/cadence/internal/common/cache"
+)
+
+type (
+	// BlobStore stores the payloads offloaded by the blob store codec.
+	// Implementations must be safe for concurrent use and must keep the blobs for as long as any workflow
+	// history referencing them can be replayed.
+	BlobStore interface {
+		// Put stores the data under the key. Keys are derived from the data, so storing the same key twice
+		// stores the same data. Payloads are encoded by workflow code, so Put is called again when the workflow
+		// is replayed by a worker which did not store the key itself; implementations should return early when
+		// the key already exists instead of uploading the data again.
+		Put(key string, data []byte) error
+		// Get returns the data stored under the key.
+		Get(key string) ([]byte, error)
+	}
+
+	// BlobStoreCodecOptions configures the codec returned by NewBlobStoreCodec.
+	BlobStoreCodecOptions struct {
+		// Required: Store holding the offloaded payloads.
+		Store BlobStore
+
+		// Optional: Payloads of at least this many bytes are offloaded to the store.
+		// default: 128KB
+		Threshold int
+	}
+
+	// blobStoreCodec replaces large payloads with references to the blob store
+	blobStoreCodec struct {
+		store     BlobStore
+		threshold int
+		// keys recently stored by the codec, encoding their payloads again skips the store
+		stored cache.Cache
+	}
+
+	// fileBlobStore stores blobs as files in a directory
+	fileBlobStore struct {
+		dir string
+	}
+)
+
+const (
+	defaultBlobStoreThreshold = 128 * 1024
+	blobStoreStoredKeysSize   = 10000
+
+	blobStoreCodecInline    byte = 0
+	blobStoreCodecReference byte = 1
+)
+
+// NewBlobStoreCodec creates a PayloadCodec that stores payloads above the threshold in the BlobStore and replaces
+// them with a reference, so only the reference is recorded in the workflow history. References are resolved when
+// decoding, including during replay. The reference is the SHA-256 digest of the payload, so encoding the same
+// payload again on replay produces the same reference. The codec remembers the keys it recently stored and does not
+// call BlobStore.Put for them again, payloads encoded on replay by another worker are passed to BlobStore.Put.
+func NewBlobStoreCodec(options BlobStoreCodecOptions) (PayloadCodec, error) {
+	if options.Store == nil {
+		return nil, errors.New("blob store is required")
+	}
+	threshold := options.Threshold
+	if threshold <= 0 {
+		threshold = defaultBlobStoreThreshold
+	}
+	return &blobStoreCodec{
+		store:     options.Store,
+		threshold: threshold,
+		stored:    cache.NewLRU(blobStoreStoredKeysSize),
+	}, nil
+}
+
+// NewBlobStoreDataConverter creates a DataConverter that offloads the payloads produced by dataConverter to the
+// BlobStore when they are above the threshold. See NewBlobStoreCodec.
+func NewBlobStoreDataConverter(dataConverter DataConverter, options BlobStoreCodecOptions) (DataConverter, error) {
+	codec, err := NewBlobStoreCodec(options)
+	if err != nil {
+		return nil, err
+	}
+	return NewCodecDataConverter(dataConverter, codec), nil
+}
+
+func (c *blobStoreCodec) Encode(data []byte) ([]byte, error) {
+	if len(data) < c.threshold {
+		return append([]byte{blobStoreCodecInline}, data...), nil
+	}
+	digest := sha256.Sum256(data)
+	key := hex.EncodeToString(digest[:])
+	if !c.stored.Exist(key) {
+		if err := c.store.Put(key, data); err != nil {
+			return nil, fmt.Errorf("unable to offload payload to blob store: %v", err)
+		}
+		c.stored.Put(key, struct{}{})
+	}
+	return append([]byte{blobStoreCodecReference}, key...), nil
+}
+
+func (c *blobStoreCodec) Decode(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.New("payload is not encoded by the blob store codec")
+	}
+	switch data[0] {
+	case blobStoreCodecInline:
+		return data[1:], nil
+	case blobStoreCodecReference:
+		key := string(data[1:])
+		result, err := c.store.Get(key)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load payload %v from blob store: %v", key, err)
+		}
+		return result, nil
+	default:
+		return nil, errors.New("payload is not encoded by the blob store codec")
+	}
+}
+
+// NewFileBlobStore creates a BlobStore keeping every blob in a file of the directory. It is meant for tests and
+// local development, production deployments need a store shared by all the workers.
+func NewFileBlobStore(dir string) (BlobStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &fileBlobStore{dir: dir}, nil
+}
+
+func (s *fileBlobStore) Put(key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	// write to a temporary file first, so readers never observe a partially written blob
+	f, err := ioutil.TempFile(s.dir, key+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func (s *fileBlobStore) Get(key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(path)
+}
+
+func (s *fileBlobStore) path(key string) (string, error) {
+	if key == "" || filepath.Base(key) != key || key == "." || key == ".." {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, key), nil
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testBlobStore keeps the blobs in memory
+type testBlobStore struct {
+	sync.Mutex
+	blobs map[string][]byte
+	puts  int
+}
+
+func (s *testBlobStore) Put(key string, data []byte) error {
+	s.Lock()
+	defer s.Unlock()
+	s.blobs[key] = data
+	s.puts++
+	return nil
+}
+
+func (s *testBlobStore) Get(key string) ([]byte, error) {
+	s.Lock()
+	defer s.Unlock()
+	return s.blobs[key], nil
+}
+
+func TestBlobStoreDataConverter(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "blobstore")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	store, err := NewFileBlobStore(dir)
+	require.NoError(t, err)
+	dc, err := NewBlobStoreDataConverter(nil, BlobStoreCodecOptions{Store: store, Threshold: 64})
+	require.NoError(t, err)
+
+	small, err := dc.ToData("small")
+	require.NoError(t, err)
+	var result string
+	require.NoError(t, dc.FromData(small, &result))
+	require.Equal(t, "small", result)
+
+	large := strings.Repeat("large", 100)
+	reference, err := dc.ToData(large)
+	require.NoError(t, err)
+	require.True(t, len(reference) < 100, "only the reference is written")
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	require.NoError(t, dc.FromData(reference, &result))
+	require.Equal(t, large, result)
+
+	// encoding the same payload again results in the same reference
+	again, err := dc.ToData(large)
+	require.NoError(t, err)
+	require.Equal(t, reference, again)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, files[0].Name())))
+	require.Error(t, dc.FromData(reference, &result))
+
+	_, err = store.Get("../escape")
+	require.Error(t, err)
+}
+
+func TestBlobStoreDataConverter_Workflow(t *testing.T) {
+	t.Parallel()
+	store := &testBlobStore{blobs: make(map[string][]byte)}
+	dc, err := NewBlobStoreDataConverter(nil, BlobStoreCodecOptions{Store: store, Threshold: 64})
+	require.NoError(t, err)
+	large := strings.Repeat("payload", 100)
+
+	activityFn := func(ctx context.Context, input string) (string, error) {
+		return strings.ToUpper(input), nil
+	}
+	workflowFn := func(ctx Context, input string) (string, error) {
+		ctx = WithActivityOptions(ctx, ActivityOptions{
+			ScheduleToStartTimeout: time.Minute,
+			StartToCloseTimeout:    time.Minute,
+		})
+		var result string
+		err := ExecuteActivity(ctx, activityFn, input).Get(ctx, &result)
+		return result, err
+	}
+
+	testSuite := &WorkflowTestSuite{}
+	env := testSuite.NewTestWorkflowEnvironment()
+	env.SetWorkerOptions(WorkerOptions{DataConverter: dc})
+	env.RegisterWorkflow(workflowFn)
+	env.RegisterActivity(activityFn)
+	env.ExecuteWorkflow(workflowFn, large)
+	require.NoError(t, env.GetWorkflowError())
+	var result string
+	require.NoError(t, env.GetWorkflowResult(&result))
+	require.Equal(t, strings.ToUpper(large), result)
+	require.Len(t, store.blobs, 2)
+}
+
+func TestBlobStoreCodec(t *testing.T) {
+	t.Parallel()
+	_, err := NewBlobStoreCodec(BlobStoreCodecOptions{})
+	require.EqualError(t, err, "blob store is required")
+
+	store := &testBlobStore{blobs: make(map[string][]byte)}
+	codec, err := NewBlobStoreCodec(BlobStoreCodecOptions{Store: store, Threshold: 4})
+	require.NoError(t, err)
+	first, err := codec.Encode([]byte("payload"))
+	require.NoError(t, err)
+	second, err := codec.Encode([]byte("payload"))
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+	require.Equal(t, 1, store.puts, "payloads stored before are not stored again")
+}
@@ -42,6 +42,58 @@ const (
 	// QueryTypeOpenSessions is the build in query type for Client.QueryWorkflow() call. Use this query type to get all open
 	// sessions in the workflow. The result will be a list of SessionInfo encoded in the EncodedValue.
 	QueryTypeOpenSessions string = "__open_sessions"
+
+	// QueryTypePendingOperations is the build in query type for Client.QueryWorkflow() call. Use this query type to get
+	// the operations the workflow is waiting on. The result will be a PendingOperations encoded in the EncodedValue.
+	QueryTypePendingOperations string = "__pending_operations"
+)
+
+type (
+	// PendingOperations is the result of the QueryTypePendingOperations query. It lists the activities, timers,
+	// child workflows and requests to external workflows that were scheduled by the workflow and are not completed
+	// yet, together with the names of the query and signal handlers registered by the workflow code.
+	PendingOperations struct {
+		Activities      []PendingActivityInfo
+		Timers          []PendingTimerInfo
+		ChildWorkflows  []PendingChildWorkflowInfo
+		ExternalSignals []PendingExternalWorkflowInfo
+		ExternalCancels []PendingExternalWorkflowInfo
+		QueryHandlers   []string
+		SignalHandlers  []string
+	}
+
+	// PendingActivityInfo describes an activity that is not completed yet.
+	PendingActivityInfo struct {
+		ActivityID   string
+		ActivityType string
+		// State of the activity as tracked by the workflow, e.g. Initiated or CancellationDecisionSent.
+		State string
+	}
+
+	// PendingTimerInfo describes a timer that has not fired yet.
+	PendingTimerInfo struct {
+		TimerID            string
+		StartToFireTimeout time.Duration
+		State              string
+	}
+
+	// PendingChildWorkflowInfo describes a child workflow that is not closed yet.
+	PendingChildWorkflowInfo struct {
+		Domain       string
+		WorkflowID   string
+		WorkflowType string
+		State        string
+	}
+
+	// PendingExternalWorkflowInfo describes a signal or a cancellation request sent to another workflow that is not
+	// acknowledged yet. SignalName is only set for signals.
+	PendingExternalWorkflowInfo struct {
+		Domain     string
+		WorkflowID string
+		RunID      string
+		SignalName string
+		State      string
+	}
 )
 
 type (
@@ -148,6 +200,34 @@ type (
 		//	- InternalServiceError
 		TerminateWorkflow(ctx context.Context, workflowID string, runID string, reason string, details []byte) error
 
+		// ResetWorkflow resets a workflow execution to an earlier decision and returns the new run. The current run
+		// is terminated and the new run replays the history up to the reset point selected by request.ResetPoint,
+		// see ResetPointFirstDecisionCompleted, ResetPointLastDecisionCompleted, ResetPointBadBinary and ResetPointEventID.
+		// The errors it can return:
+		//	- EntityNotExistsError
+		//	- BadRequestError
+		//	- InternalServiceError
+		ResetWorkflow(ctx context.Context, request *ResetWorkflowRequest) (*WorkflowExecution, error)
+
+		// ResetWorkflows resets every workflow execution matching the visibility query request.Query to
+		// request.ResetPoint. It is BatchWorkflow with a BatchReset operation: executions which fail to reset are
+		// reported in BatchWorkflowResponse.Failures and an interrupted call can be resumed from its Checkpoint.
+		// This API only works with ElasticSearch.
+		// The errors it can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		ResetWorkflows(ctx context.Context, request *ResetWorkflowsRequest) (*BatchWorkflowResponse, error)
+
+		// BatchWorkflow applies request.Operation to every workflow execution matching the visibility query
+		// request.Query with bounded concurrency and rate, see BatchCancel, BatchTerminate, BatchSignal and BatchReset.
+		// Per-execution failures are reported in BatchWorkflowResponse.Failures and through request.ProgressHandler.
+		// If an error is returned, BatchWorkflowResponse.Checkpoint can be used to resume the batch.
+		// This API only works with ElasticSearch.
+		// The errors it can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		BatchWorkflow(ctx context.Context, request *BatchWorkflowRequest) (*BatchWorkflowResponse, error)
+
 		// GetWorkflowHistory gets history events of a particular workflow
 		// - workflow ID of the workflow.
 		// - runID can be default(empty string). if empty string then it will pick the last running execution of that workflow ID.
@@ -301,6 +381,36 @@ type (
 		//  - QueryFailError
 		QueryWorkflowWithOptions(ctx context.Context, request *QueryWorkflowWithOptionsRequest) (*QueryWorkflowWithOptionsResponse, error)
 
+		// UpdateWorkflow sends an update to a given workflow execution and waits for the update handler to produce
+		// the result. Parameter workflowID and updateName are required, other parameters are optional. Unlike
+		// SignalWorkflow, the caller observes the outcome of the update, unlike QueryWorkflow, the handler may
+		// mutate the workflow state.
+		// See comments at workflow.SetUpdateHandler(ctx Context, updateName string, handler interface{}, options UpdateHandlerOptions)
+		// for more details on how to setup update handler within the target workflow.
+		// - workflowID is required.
+		// - runID can be default(empty string). if empty string then it will pick the running execution of that workflow ID.
+		// - updateName is the name of the update.
+		// - args... are the optional update parameters.
+		// The errors it can return:
+		//  - UpdateRejectedError
+		//  - the error returned by the update handler
+		//  - BadRequestError
+		//  - InternalServiceError
+		//  - EntityNotExistError
+		//  - QueryFailError
+		UpdateWorkflow(ctx context.Context, workflowID string, runID string, updateName string, args ...interface{}) (Value, error)
+
+		// UpdateWorkflowWithOptions sends an update to a given workflow execution and waits for the update handler
+		// to produce the result. See UpdateWorkflowWithOptionsRequest for more information.
+		// The errors it can return:
+		//  - UpdateRejectedError
+		//  - the error returned by the update handler
+		//  - BadRequestError
+		//  - InternalServiceError
+		//  - EntityNotExistError
+		//  - QueryFailError
+		UpdateWorkflowWithOptions(ctx context.Context, request *UpdateWorkflowWithOptionsRequest) (Value, error)
+
 		// DescribeWorkflowExecution returns information about the specified workflow execution.
 		// The errors it can return:
 		//  - BadRequestError
@@ -324,6 +434,10 @@ type (
 		DataConverter      DataConverter
 		Tracer             opentracing.Tracer
 		ContextPropagators []ContextPropagator
+
+		// Optional: Specifies factories used to instantiate client interceptor chain
+		// The chain is instantiated once per client and wraps every call done through it
+		Interceptors []ClientInterceptorFactory
 	}
 
 	// StartWorkflowOptions configuration parameters for starting a workflow execution.
@@ -516,7 +630,7 @@ func NewClient(service workflowserviceclient.Interface, domain string, options *
 	} else {
 		tracer = opentracing.NoopTracer{}
 	}
-	return &workflowClient{
+	wc := &workflowClient{
 		workflowService:    metrics.NewWorkflowServiceWrapper(service, metricScope),
 		domain:             domain,
 		registry:           newRegistry(),
@@ -526,6 +640,14 @@ func NewClient(service workflowserviceclient.Interface, domain string, options *
 		contextPropagators: contextPropagators,
 		tracer:             tracer,
 	}
+	var client ClientInterceptor = wc
+	if options != nil {
+		for i := len(options.Interceptors) - 1; i >= 0; i-- {
+			client = options.Interceptors[i].NewInterceptor(client)
+		}
+	}
+	wc.interceptedClient = client
+	return client
 }
 
 // NewDomainClient creates an instance of a domain client, to manager lifecycle of domains.
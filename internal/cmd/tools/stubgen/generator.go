@@ -0,0 +1,496 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const (
+	workflowImportPath = "go.uber.org/cadence/workflow"
+	clientImportPath   = "go.uber.org/cadence/client"
+	contextImportPath  = "context"
+)
+
+type (
+	// sourcePackage holds the workflow and activity functions found in a package
+	sourcePackage struct {
+		name       string
+		workflows  []*stubFunc
+		activities []*stubFunc
+		// import path of every package referenced by the parameter or result types, keyed by its name
+		imports map[string]string
+		skipped []string
+	}
+
+	// stubFunc describes a single workflow or activity function
+	stubFunc struct {
+		Name   string
+		Params []stubParam
+		// Result is the type of the non error result, empty when the function returns error only
+		Result string
+	}
+
+	stubParam struct {
+		Name string
+		Type string
+	}
+
+	// fileScope resolves the identifiers used in the declarations of a single source file
+	fileScope struct {
+		pkgName string
+		imports map[string]string
+		used    map[string]string
+	}
+)
+
+// names used by the generated helpers which must not be shadowed by parameters
+var reservedParamNames = map[string]bool{
+	"ctx":      true,
+	"c":        true,
+	"options":  true,
+	"f":        true,
+	"err":      true,
+	"result":   true,
+	"run":      true,
+	"workflow": true,
+	"client":   true,
+	"context":  true,
+}
+
+func parsePackage(dir string) (*sourcePackage, error) {
+	fset := token.NewFileSet()
+	notTest := func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}
+	pkgs, err := parser.ParseDir(fset, dir, notTest, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected exactly one package in %v, found %v", dir, len(pkgs))
+	}
+
+	src := &sourcePackage{imports: make(map[string]string)}
+	for name, pkg := range pkgs {
+		src.name = name
+		fileNames := make([]string, 0, len(pkg.Files))
+		for fileName := range pkg.Files {
+			fileNames = append(fileNames, fileName)
+		}
+		sort.Strings(fileNames)
+		for _, fileName := range fileNames {
+			if err := src.addFile(pkg.Files[fileName]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return src, nil
+}
+
+func (p *sourcePackage) addFile(file *ast.File) error {
+	scope := &fileScope{pkgName: p.name, imports: make(map[string]string)}
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return err
+		}
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		scope.imports[name] = importPath
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || !fn.Name.IsExported() {
+			continue
+		}
+		params := fn.Type.Params.List
+		if len(params) == 0 {
+			continue
+		}
+		var target *[]*stubFunc
+		switch scope.importPathOf(params[0].Type) {
+		case workflowImportPath:
+			target = &p.workflows
+		case contextImportPath:
+			target = &p.activities
+		default:
+			continue
+		}
+
+		scope.used = make(map[string]string)
+		stub, err := scope.newStubFunc(fn)
+		if err != nil {
+			p.skipped = append(p.skipped, fmt.Sprintf("%v: %v", fn.Name.Name, err))
+			continue
+		}
+		for name, importPath := range scope.used {
+			if existing, ok := p.imports[name]; ok && existing != importPath {
+				return fmt.Errorf("import name %v refers to both %v and %v", name, existing, importPath)
+			}
+			p.imports[name] = importPath
+		}
+		*target = append(*target, stub)
+	}
+	return nil
+}
+
+// importPathOf returns the import path of the package when expr is <package>.Context
+func (s *fileScope) importPathOf(expr ast.Expr) string {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Context" {
+		return ""
+	}
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	return s.imports[x.Name]
+}
+
+func (s *fileScope) newStubFunc(fn *ast.FuncDecl) (*stubFunc, error) {
+	stub := &stubFunc{Name: fn.Name.Name}
+
+	index := 0
+	for i, field := range fn.Type.Params.List {
+		if _, ok := field.Type.(*ast.Ellipsis); ok {
+			return nil, fmt.Errorf("variadic parameters are not supported")
+		}
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{nil}
+		}
+		if i == 0 {
+			// the context parameter is supplied by the generated helpers
+			index++
+			names = names[1:]
+			if len(names) == 0 {
+				continue
+			}
+		}
+		typ, err := s.typeString(field.Type)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			index++
+			paramName := fmt.Sprintf("arg%d", index-1)
+			if name != nil && name.Name != "_" && !reservedParamNames[name.Name] && s.imports[name.Name] == "" &&
+				name.Name != s.pkgName {
+				paramName = name.Name
+			}
+			stub.Params = append(stub.Params, stubParam{Name: paramName, Type: typ})
+		}
+	}
+
+	var results []ast.Expr
+	if fn.Type.Results != nil {
+		for _, field := range fn.Type.Results.List {
+			for i := 0; i < len(field.Names) || i == 0; i++ {
+				results = append(results, field.Type)
+			}
+		}
+	}
+	if len(results) == 0 || len(results) > 2 || !isErrorType(results[len(results)-1]) {
+		return nil, fmt.Errorf("expected error or (result, error) return values")
+	}
+	if len(results) == 2 {
+		typ, err := s.typeString(results[0])
+		if err != nil {
+			return nil, err
+		}
+		stub.Result = typ
+	}
+	return stub, nil
+}
+
+func isErrorType(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == "error"
+}
+
+// typeString renders the type as seen from outside of the source package
+func (s *fileScope) typeString(expr ast.Expr) (string, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if types.Universe.Lookup(t.Name) != nil {
+			return t.Name, nil
+		}
+		if !t.IsExported() {
+			return "", fmt.Errorf("unexported type %v", t.Name)
+		}
+		return s.pkgName + "." + t.Name, nil
+	case *ast.SelectorExpr:
+		x, ok := t.X.(*ast.Ident)
+		if !ok || s.imports[x.Name] == "" {
+			return "", fmt.Errorf("unsupported type %v", t.Sel.Name)
+		}
+		s.used[x.Name] = s.imports[x.Name]
+		return x.Name + "." + t.Sel.Name, nil
+	case *ast.StarExpr:
+		elem, err := s.typeString(t.X)
+		return "*" + elem, err
+	case *ast.ArrayType:
+		elem, err := s.typeString(t.Elt)
+		if err != nil || t.Len == nil {
+			return "[]" + elem, err
+		}
+		length, ok := t.Len.(*ast.BasicLit)
+		if !ok {
+			return "", fmt.Errorf("unsupported array length")
+		}
+		return "[" + length.Value + "]" + elem, nil
+	case *ast.MapType:
+		key, err := s.typeString(t.Key)
+		if err != nil {
+			return "", err
+		}
+		value, err := s.typeString(t.Value)
+		return "map[" + key + "]" + value, err
+	case *ast.InterfaceType:
+		if len(t.Methods.List) == 0 {
+			return "interface{}", nil
+		}
+	case *ast.StructType:
+		if len(t.Fields.List) == 0 {
+			return "struct{}", nil
+		}
+	}
+	return "", fmt.Errorf("unsupported type expression %T", expr)
+}
+
+func generate(src *sourcePackage, outPkg, srcImport string) ([]byte, error) {
+	if len(src.workflows) == 0 && len(src.activities) == 0 {
+		return nil, fmt.Errorf("no workflow or activity functions found in package %v", src.name)
+	}
+	if err := checkNameCollisions(src); err != nil {
+		return nil, err
+	}
+	imports := map[string]string{"workflow": workflowImportPath}
+	if len(src.workflows) > 0 {
+		imports["client"] = clientImportPath
+		imports["context"] = contextImportPath
+	}
+	addImport := func(name, importPath string) error {
+		if existing, ok := imports[name]; ok && existing != importPath {
+			return fmt.Errorf("import name %v refers to both %v and %v", name, existing, importPath)
+		}
+		imports[name] = importPath
+		return nil
+	}
+	if err := addImport(src.name, srcImport); err != nil {
+		return nil, err
+	}
+	for name, importPath := range src.imports {
+		if err := addImport(name, importPath); err != nil {
+			return nil, err
+		}
+	}
+
+	// standard library imports go first, followed by all the others
+	importSpecs := make([][]string, 2)
+	for name, importPath := range imports {
+		spec := strconv.Quote(importPath)
+		if path.Base(importPath) != name {
+			spec = name + " " + spec
+		}
+		group := 0
+		if strings.Contains(strings.Split(importPath, "/")[0], ".") {
+			group = 1
+		}
+		importSpecs[group] = append(importSpecs[group], spec)
+	}
+	for _, group := range importSpecs {
+		sort.Slice(group, func(i, j int) bool {
+			return strings.Trim(group[i][strings.Index(group[i], `"`):], `"`) <
+				strings.Trim(group[j][strings.Index(group[j], `"`):], `"`)
+		})
+	}
+
+	var buf bytes.Buffer
+	err := stubTemplate.Execute(&buf, map[string]interface{}{
+		"Package":    outPkg,
+		"Source":     src.name,
+		"Imports":    importSpecs,
+		"Workflows":  src.workflows,
+		"Activities": src.activities,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// checkNameCollisions fails when two functions would produce the same generated declaration,
+// e.g. the ExecuteChildX helper of workflow X and the ExecuteX helper of activity ChildX.
+func checkNameCollisions(src *sourcePackage) error {
+	declared := make(map[string]string)
+	declare := func(owner string, names ...string) error {
+		for _, name := range names {
+			if existing, ok := declared[name]; ok {
+				return fmt.Errorf("generated declaration %v of %v collides with the one of %v", name, owner, existing)
+			}
+			declared[name] = owner
+		}
+		return nil
+	}
+	for _, fn := range src.activities {
+		err := declare("activity "+fn.Name, fn.Name+"Future", "Execute"+fn.Name, "ExecuteLocal"+fn.Name)
+		if err != nil {
+			return err
+		}
+	}
+	for _, fn := range src.workflows {
+		err := declare("workflow "+fn.Name,
+			fn.Name+"ChildFuture", "ExecuteChild"+fn.Name, fn.Name+"Run", "Start"+fn.Name, "Execute"+fn.Name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var stubTemplate = template.Must(template.New("stubs").Funcs(template.FuncMap{
+	"params": func(params []stubParam) string {
+		var b strings.Builder
+		for _, p := range params {
+			b.WriteString(", " + p.Name + " " + p.Type)
+		}
+		return b.String()
+	},
+	"args": func(params []stubParam) string {
+		var b strings.Builder
+		for _, p := range params {
+			b.WriteString(", " + p.Name)
+		}
+		return b.String()
+	},
+}).Parse(`// Code generated by stubgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range $i, $group := .Imports}}
+{{- if $i}}
+{{end}}
+{{- range $group}}
+	{{.}}
+{{- end}}
+{{- end}}
+)
+{{$src := .Source}}
+{{- range .Activities}}
+// {{.Name}}Future is the typed future returned by Execute{{.Name}} and ExecuteLocal{{.Name}}
+type {{.Name}}Future struct {
+	Future workflow.Future
+}
+
+// Get blocks until the {{.Name}} activity completes and returns its result
+{{- if .Result}}
+func (f {{.Name}}Future) Get(ctx workflow.Context) ({{.Result}}, error) {
+	var result {{.Result}}
+	err := f.Future.Get(ctx, &result)
+	return result, err
+}
+{{- else}}
+func (f {{.Name}}Future) Get(ctx workflow.Context) error {
+	return f.Future.Get(ctx, nil)
+}
+{{- end}}
+
+// Execute{{.Name}} schedules the {{$src}}.{{.Name}} activity, see workflow.ExecuteActivity
+func Execute{{.Name}}(ctx workflow.Context{{params .Params}}) {{.Name}}Future {
+	return {{.Name}}Future{Future: workflow.ExecuteActivity(ctx, {{$src}}.{{.Name}}{{args .Params}})}
+}
+
+// ExecuteLocal{{.Name}} runs the {{$src}}.{{.Name}} activity as a local activity, see workflow.ExecuteLocalActivity
+func ExecuteLocal{{.Name}}(ctx workflow.Context{{params .Params}}) {{.Name}}Future {
+	return {{.Name}}Future{Future: workflow.ExecuteLocalActivity(ctx, {{$src}}.{{.Name}}{{args .Params}})}
+}
+{{end}}
+{{- range .Workflows}}
+// {{.Name}}ChildFuture is the typed future returned by ExecuteChild{{.Name}}
+type {{.Name}}ChildFuture struct {
+	Future workflow.ChildWorkflowFuture
+}
+
+// Get blocks until the {{.Name}} child workflow completes and returns its result
+{{- if .Result}}
+func (f {{.Name}}ChildFuture) Get(ctx workflow.Context) ({{.Result}}, error) {
+	var result {{.Result}}
+	err := f.Future.Get(ctx, &result)
+	return result, err
+}
+{{- else}}
+func (f {{.Name}}ChildFuture) Get(ctx workflow.Context) error {
+	return f.Future.Get(ctx, nil)
+}
+{{- end}}
+
+// ExecuteChild{{.Name}} starts the {{$src}}.{{.Name}} workflow as a child workflow, see workflow.ExecuteChildWorkflow
+func ExecuteChild{{.Name}}(ctx workflow.Context{{params .Params}}) {{.Name}}ChildFuture {
+	return {{.Name}}ChildFuture{Future: workflow.ExecuteChildWorkflow(ctx, {{$src}}.{{.Name}}{{args .Params}})}
+}
+
+// {{.Name}}Run is the typed handle returned by Execute{{.Name}}
+type {{.Name}}Run struct {
+	Run client.WorkflowRun
+}
+
+// Get blocks until the {{.Name}} workflow completes and returns its result
+{{- if .Result}}
+func (r {{.Name}}Run) Get(ctx context.Context) ({{.Result}}, error) {
+	var result {{.Result}}
+	err := r.Run.Get(ctx, &result)
+	return result, err
+}
+{{- else}}
+func (r {{.Name}}Run) Get(ctx context.Context) error {
+	return r.Run.Get(ctx, nil)
+}
+{{- end}}
+
+// Start{{.Name}} starts the {{$src}}.{{.Name}} workflow, see client.Client.StartWorkflow
+func Start{{.Name}}(ctx context.Context, c client.Client, options client.StartWorkflowOptions{{params .Params}}) (*workflow.Execution, error) {
+	return c.StartWorkflow(ctx, options, {{$src}}.{{.Name}}{{args .Params}})
+}
+
+// Execute{{.Name}} starts the {{$src}}.{{.Name}} workflow and returns a typed handle to its run, see client.Client.ExecuteWorkflow
+func Execute{{.Name}}(ctx context.Context, c client.Client, options client.StartWorkflowOptions{{params .Params}}) ({{.Name}}Run, error) {
+	run, err := c.ExecuteWorkflow(ctx, options, {{$src}}.{{.Name}}{{args .Params}})
+	return {{.Name}}Run{Run: run}, err
+}
+{{end}}`))
@@ -0,0 +1,109 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type (
+	// command line config params
+	config struct {
+		srcDir    string
+		srcImport string
+		outFile   string
+		outPkg    string
+	}
+)
+
+// default perms for the generated files
+var defaultFilePerms = os.FileMode(0644)
+
+// command line utility that generates typed stubs for the workflow and activity functions
+// declared in a package. Usage as follows:
+//
+//  go run ./internal/cmd/tools/stubgen -src ./path/to/workflows -out ./path/to/workflowstub/stubs.go
+//
+// Exported top level functions are picked up when their first parameter is workflow.Context (workflows) or
+// context.Context (activities) and they return either error or (result, error). For every workflow the generator
+// emits typed helpers to start it through client.Client and to execute it as a child workflow, for every activity
+// it emits typed helpers to execute it as a regular or local activity. All helpers return typed futures, so argument
+// and result type mismatches are reported by the compiler instead of at runtime.
+func main() {
+	var cfg config
+	flag.StringVar(&cfg.srcDir, "src", ".", "directory of the package that declares workflows and activities")
+	flag.StringVar(&cfg.srcImport, "srcImport", "",
+		"import path of the source package, resolved with go list when empty")
+	flag.StringVar(&cfg.outFile, "out", "", "generated file, defaults to <src>stub/stubs.go")
+	flag.StringVar(&cfg.outPkg, "pkg", "", "package name of the generated file, defaults to <src package>stub")
+	flag.Parse()
+
+	if err := run(&cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(-1)
+	}
+}
+
+func run(cfg *config) error {
+	src, err := parsePackage(cfg.srcDir)
+	if err != nil {
+		return err
+	}
+	if cfg.outPkg == "" {
+		cfg.outPkg = src.name + "stub"
+	}
+	if cfg.outFile == "" {
+		cfg.outFile = filepath.Join(cfg.srcDir, cfg.outPkg, "stubs.go")
+	}
+	if cfg.srcImport == "" {
+		if cfg.srcImport, err = resolveImportPath(cfg.srcDir); err != nil {
+			return err
+		}
+	}
+	for _, skipped := range src.skipped {
+		fmt.Fprintln(os.Stderr, "stubgen: skipping", skipped)
+	}
+
+	data, err := generate(src, cfg.outPkg, cfg.srcImport)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.outFile), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(cfg.outFile, data, defaultFilePerms)
+}
+
+func resolveImportPath(dir string) (string, error) {
+	cmd := exec.Command("go", "list", "-f", "{{.ImportPath}}", ".")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve import path of %v, use -srcImport: %v", dir, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
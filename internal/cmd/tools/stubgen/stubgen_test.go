@@ -0,0 +1,167 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+)
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
+
+func TestParsePackage(t *testing.T) {
+	src, err := parsePackage("testdata/sample")
+	require.NoError(t, err)
+
+	assert.Equal(t, "sample", src.name)
+	assert.Equal(t, []*stubFunc{
+		{
+			Name: "ProcessOrder",
+			Params: []stubParam{
+				{Name: "order", Type: "sample.Order"},
+				{Name: "deadline", Type: "time.Duration"},
+			},
+			Result: "*sample.Receipt",
+		},
+		{
+			Name:   "Notify",
+			Params: []stubParam{{Name: "recipients", Type: "map[string][]string"}},
+		},
+	}, src.workflows)
+	assert.Equal(t, []*stubFunc{
+		{
+			Name: "Charge",
+			Params: []stubParam{
+				{Name: "orderID", Type: "string"},
+				{Name: "amount", Type: "float64"},
+				{Name: "ctxValue", Type: "int"},
+			},
+			Result: "string",
+		},
+		{Name: "Ping"},
+	}, src.activities)
+	assert.Equal(t, map[string]string{"time": "time"}, src.imports)
+	assert.Len(t, src.skipped, 2)
+}
+
+func TestGenerate(t *testing.T) {
+	src, err := parsePackage("testdata/sample")
+	require.NoError(t, err)
+
+	data, err := generate(src, "samplestub", "example.com/sample")
+	require.NoError(t, err)
+
+	file, err := parser.ParseFile(token.NewFileSet(), "stubs.go", data, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "samplestub", file.Name.Name)
+
+	var imports []string
+	for _, spec := range file.Imports {
+		imports = append(imports, spec.Path.Value)
+	}
+	assert.ElementsMatch(t, []string{
+		`"context"`,
+		`"example.com/sample"`,
+		`"go.uber.org/cadence/client"`,
+		`"go.uber.org/cadence/workflow"`,
+		`"time"`,
+	}, imports)
+
+	for _, name := range []string{
+		"ExecuteCharge", "ExecuteLocalCharge", "ExecutePing", "ExecuteLocalPing",
+		"ExecuteChildProcessOrder", "StartProcessOrder", "ExecuteProcessOrder",
+		"ExecuteChildNotify", "StartNotify", "ExecuteNotify",
+	} {
+		assert.NotNil(t, file.Scope.Lookup(name), name)
+	}
+	for _, name := range []string{"Variadic", "Internal", "Helper"} {
+		assert.NotContains(t, string(data), name)
+	}
+	assert.Contains(t, string(data),
+		"func (f ProcessOrderChildFuture) Get(ctx workflow.Context) (*sample.Receipt, error)")
+	assert.Contains(t, string(data),
+		"func ExecuteCharge(ctx workflow.Context, orderID string, amount float64, ctxValue int) ChargeFuture")
+	assert.Contains(t, string(data), "func (f PingFuture) Get(ctx workflow.Context) error")
+
+	typeCheck(t, data)
+}
+
+// typeCheck type-checks the generated stubs against the sample package and the cadence packages they use
+func typeCheck(t *testing.T, data []byte) {
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedName | packages.NeedTypes},
+		"context", "time", workflowImportPath, clientImportPath)
+	require.NoError(t, err)
+	require.Len(t, pkgs, 4)
+	imported := make(map[string]*types.Package)
+	for _, pkg := range pkgs {
+		require.Empty(t, pkg.Errors, pkg.PkgPath)
+		require.NotNil(t, pkg.Types, pkg.PkgPath)
+		// loaders that can't read the export data of the toolchain return empty packages without an error
+		require.True(t, pkg.Types.Complete() && pkg.Types.Scope().Len() > 0, "%v is not loaded", pkg.PkgPath)
+		imported[pkg.PkgPath] = pkg.Types
+	}
+	conf := types.Config{Importer: importerFunc(func(path string) (*types.Package, error) {
+		if pkg, ok := imported[path]; ok {
+			return pkg, nil
+		}
+		return nil, fmt.Errorf("unexpected import %v", path)
+	})}
+
+	// the sample package lives in testdata, so it is checked from source
+	fset := token.NewFileSet()
+	sample, err := parser.ParseFile(fset, "testdata/sample/sample.go", nil, 0)
+	require.NoError(t, err)
+	imported["example.com/sample"], err = conf.Check("example.com/sample", fset, []*ast.File{sample}, nil)
+	require.NoError(t, err)
+
+	stubs, err := parser.ParseFile(fset, "stubs.go", data, 0)
+	require.NoError(t, err)
+	_, err = conf.Check("example.com/samplestub", fset, []*ast.File{stubs}, nil)
+	require.NoError(t, err)
+}
+
+func TestGenerate_NameCollision(t *testing.T) {
+	src := &sourcePackage{
+		name:       "sample",
+		workflows:  []*stubFunc{{Name: "Refund"}},
+		activities: []*stubFunc{{Name: "ChildRefund"}},
+	}
+	_, err := generate(src, "samplestub", "example.com/sample")
+	assert.EqualError(t, err,
+		"generated declaration ExecuteChildRefund of workflow Refund collides with the one of activity ChildRefund")
+}
+
+func TestGenerate_NoFunctions(t *testing.T) {
+	_, err := generate(&sourcePackage{name: "empty"}, "emptystub", "example.com/empty")
+	assert.Error(t, err)
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package sample
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/cadence/workflow"
+)
+
+type (
+	// Order is a sample workflow input
+	Order struct {
+		ID    string
+		Items []string
+	}
+
+	// Receipt is a sample workflow result
+	Receipt struct {
+		Total float64
+	}
+
+	internalState struct{}
+)
+
+// ProcessOrder is a sample workflow
+func ProcessOrder(ctx workflow.Context, order Order, deadline time.Duration) (*Receipt, error) {
+	return nil, nil
+}
+
+// Notify is a sample workflow without a result
+func Notify(ctx workflow.Context, recipients map[string][]string) error {
+	return nil
+}
+
+// Charge is a sample activity
+func Charge(ctx context.Context, orderID string, amount float64, ctxValue int) (string, error) {
+	return "", nil
+}
+
+// Ping is a sample activity without parameters and result
+func Ping(ctx context.Context) error {
+	return nil
+}
+
+// Variadic is skipped as variadic parameters are not supported
+func Variadic(ctx context.Context, values ...string) error {
+	return nil
+}
+
+// Internal is skipped as it uses an unexported type
+func Internal(ctx workflow.Context, state internalState) error {
+	return nil
+}
+
+// Helper is not a workflow or activity
+func Helper(value string) string {
+	return value
+}
+
+func unexported(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,488 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const (
+	// ignoreDirective suppresses the report of a construct on the same or on the next line. Placed in the doc
+	// comment of a function it marks the whole function as deterministic.
+	ignoreDirective = "workflowcheck:ignore"
+
+	cadencePkgPrefix = "go.uber.org/cadence"
+	workflowPkgPath  = "go.uber.org/cadence/workflow"
+)
+
+// Analyzer reports workflow functions that directly or through the functions they call use constructs that
+// are not deterministic on replay.
+var Analyzer = &analysis.Analyzer{
+	Name:      "workflowcheck",
+	Doc:       "reports non-deterministic code reachable from registered cadence workflow functions",
+	Run:       run,
+	FactTypes: []analysis.Fact{new(nonDeterministicFact)},
+}
+
+// nonDeterministicFact is exported for every function that is not safe to call from workflow code, so
+// the workflows registered in other packages can be checked as well.
+type nonDeterministicFact struct {
+	Reason string
+}
+
+func (*nonDeterministicFact) AFact() {}
+
+func (f *nonDeterministicFact) String() string {
+	return "nonDeterministic(" + f.Reason + ")"
+}
+
+// forbiddenFuncs are the functions that must be replaced with their workflow package equivalents.
+var forbiddenFuncs = map[string]map[string]string{
+	"time": {
+		"Now":       "workflow.Now",
+		"Since":     "workflow.Now",
+		"Until":     "workflow.Now",
+		"Sleep":     "workflow.Sleep",
+		"After":     "workflow.NewTimer",
+		"AfterFunc": "workflow.NewTimer",
+		"NewTimer":  "workflow.NewTimer",
+		"NewTicker": "workflow.NewTimer",
+		"Tick":      "workflow.NewTimer",
+	},
+	"crypto/rand": {
+		"Int":   "workflow.SideEffect",
+		"Prime": "workflow.SideEffect",
+		"Read":  "workflow.SideEffect",
+	},
+	"os": {
+		"Getenv":    "workflow.SideEffect",
+		"LookupEnv": "workflow.SideEffect",
+		"Environ":   "workflow.SideEffect",
+	},
+}
+
+// sortFuncs are the sort package functions that put the collected map keys in a deterministic order.
+var sortFuncs = map[string]bool{
+	"Strings":     true,
+	"Ints":        true,
+	"Float64s":    true,
+	"Slice":       true,
+	"SliceStable": true,
+}
+
+// deterministicRandFuncs are the math/rand functions that don't use the global source.
+var deterministicRandFuncs = map[string]bool{
+	"New":       true,
+	"NewSource": true,
+	"NewZipf":   true,
+}
+
+type checker struct {
+	pass    *analysis.Pass
+	ignored map[string]map[int]bool // file name -> lines with the ignore directive
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	if isCadencePackage(pass.Pkg.Path()) || isStandardPackage(pass.Pkg.Path()) {
+		// cadence implements the deterministic primitives on top of native ones and the standard library
+		// is covered by forbiddenFuncs.
+		return nil, nil
+	}
+
+	c := &checker{pass: pass, ignored: make(map[string]map[int]bool)}
+	for _, file := range pass.Files {
+		for _, group := range file.Comments {
+			for _, comment := range group.List {
+				if strings.Contains(comment.Text, ignoreDirective) {
+					position := pass.Fset.Position(comment.Slash)
+					if c.ignored[position.Filename] == nil {
+						c.ignored[position.Filename] = make(map[int]bool)
+					}
+					c.ignored[position.Filename][position.Line] = true
+				}
+			}
+		}
+	}
+
+	// collect the direct reasons and the callees declared in this package
+	funcs := make(map[*types.Func]*funcInfo)
+	var order []*types.Func
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Body == nil {
+				continue
+			}
+			fn, ok := pass.TypesInfo.Defs[fd.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			info := &funcInfo{}
+			if !hasIgnoreDirective(fd.Doc) {
+				c.inspect(fd.Body, info)
+			}
+			funcs[fn] = info
+			order = append(order, fn)
+		}
+	}
+
+	// propagate the reasons through the calls until nothing changes
+	for changed := true; changed; {
+		changed = false
+		for _, fn := range order {
+			info := funcs[fn]
+			if info.reason != "" {
+				continue
+			}
+			for _, callee := range info.callees {
+				if reason := c.reasonOf(callee, funcs); reason != "" {
+					info.reason = chainReason(callee, reason)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	for _, fn := range order {
+		// other packages can only reference the exported functions and methods
+		if reason := funcs[fn].reason; reason != "" && fn.Exported() {
+			pass.ExportObjectFact(fn, &nonDeterministicFact{Reason: reason})
+		}
+	}
+
+	// report the registered workflows
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 || !isWorkflowRegistration(typeutil.Callee(pass.TypesInfo, call)) {
+				return true
+			}
+			c.checkRegisteredWorkflow(call.Args[0], funcs)
+			return true
+		})
+	}
+	return nil, nil
+}
+
+// funcInfo holds the first reason why a function is not deterministic and the functions it references.
+type funcInfo struct {
+	reason  string
+	callees []*types.Func
+}
+
+func (c *checker) checkRegisteredWorkflow(arg ast.Expr, funcs map[*types.Func]*funcInfo) {
+	var name, reason string
+	switch expr := astutil.Unparen(arg).(type) {
+	case *ast.FuncLit:
+		info := &funcInfo{}
+		c.inspect(expr.Body, info)
+		name = "function literal"
+		reason = info.reason
+		for _, callee := range info.callees {
+			if reason != "" {
+				break
+			}
+			if calleeReason := c.reasonOf(callee, funcs); calleeReason != "" {
+				reason = chainReason(callee, calleeReason)
+			}
+		}
+	case *ast.Ident, *ast.SelectorExpr:
+		fn, ok := c.referencedFunc(expr)
+		if !ok {
+			return
+		}
+		name = fn.Name()
+		reason = c.reasonOf(fn, funcs)
+	default:
+		return
+	}
+	if reason != "" {
+		c.pass.Reportf(arg.Pos(), "workflow %s is not deterministic: %s", name, reason)
+	}
+}
+
+// reasonOf returns why fn is not deterministic, or an empty string if it is or if it can't be analyzed.
+func (c *checker) reasonOf(fn *types.Func, funcs map[*types.Func]*funcInfo) string {
+	if info, ok := funcs[fn]; ok {
+		return info.reason
+	}
+	var fact nonDeterministicFact
+	if fn.Pkg() != nil && c.pass.ImportObjectFact(fn, &fact) {
+		return fact.Reason
+	}
+	return ""
+}
+
+// inspect records the first non-deterministic construct of the body and all the functions it references.
+// Function literals are inspected as part of the enclosing function.
+func (c *checker) inspect(body *ast.BlockStmt, info *funcInfo) {
+	report := func(pos token.Pos, format string, args ...interface{}) {
+		if info.reason == "" && !c.isIgnored(pos) {
+			info.reason = fmt.Sprintf(format, args...) + " at " + c.position(pos)
+		}
+	}
+	sortedKeys := c.sortedKeyRanges(body)
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.GoStmt:
+			report(node.Pos(), "go statement, use workflow.Go")
+		case *ast.SelectStmt:
+			report(node.Pos(), "select statement, use workflow.Selector")
+		case *ast.SendStmt:
+			report(node.Pos(), "channel send, use workflow.Channel")
+		case *ast.UnaryExpr:
+			if node.Op == token.ARROW {
+				report(node.Pos(), "channel receive, use workflow.Channel")
+			}
+		case *ast.RangeStmt:
+			switch c.pass.TypesInfo.TypeOf(node.X).Underlying().(type) {
+			case *types.Map:
+				if !sortedKeys[node] {
+					report(node.Pos(), "iteration over map, sort the keys first")
+				}
+			case *types.Chan:
+				report(node.Pos(), "iteration over channel, use workflow.Channel")
+			}
+		case *ast.CallExpr:
+			if isMakeChan(c.pass.TypesInfo, node) {
+				report(node.Pos(), "native channel, use workflow.NewChannel")
+			}
+		case *ast.AssignStmt:
+			for _, lhs := range node.Lhs {
+				if v := c.globalVar(lhs); v != nil {
+					report(lhs.Pos(), "assignment to global variable %s", v.Name())
+				}
+			}
+		case *ast.IncDecStmt:
+			if v := c.globalVar(node.X); v != nil {
+				report(node.Pos(), "assignment to global variable %s", v.Name())
+			}
+		case *ast.Ident:
+			fn, ok := c.pass.TypesInfo.Uses[node].(*types.Func)
+			if !ok || fn.Pkg() == nil {
+				break
+			}
+			if replacement, forbidden := forbiddenFunc(fn); forbidden {
+				report(node.Pos(), "call to %s.%s, use %s", fn.Pkg().Name(), fn.Name(), replacement)
+			} else if !c.isIgnored(node.Pos()) {
+				info.callees = append(info.callees, fn)
+			}
+		}
+		return true
+	})
+}
+
+// sortedKeyRanges returns the range statements over maps that only append the keys to a slice which is sorted
+// later in the same block, the usual way to iterate over a map in a deterministic order.
+func (c *checker) sortedKeyRanges(body *ast.BlockStmt) map[*ast.RangeStmt]bool {
+	ranges := make(map[*ast.RangeStmt]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		var list []ast.Stmt
+		switch node := n.(type) {
+		case *ast.BlockStmt:
+			list = node.List
+		case *ast.CaseClause:
+			list = node.Body
+		case *ast.CommClause:
+			list = node.Body
+		}
+		for i, stmt := range list {
+			rs, ok := stmt.(*ast.RangeStmt)
+			if !ok {
+				continue
+			}
+			if keys := c.collectedKeys(rs); keys != nil && c.sortedLater(list[i+1:], keys) {
+				ranges[rs] = true
+			}
+		}
+		return true
+	})
+	return ranges
+}
+
+// collectedKeys returns the slice of a range statement whose body is only keys = append(keys, key).
+func (c *checker) collectedKeys(rs *ast.RangeStmt) types.Object {
+	key, ok := rs.Key.(*ast.Ident)
+	if !ok || (rs.Value != nil && !isBlank(rs.Value)) || len(rs.Body.List) != 1 {
+		return nil
+	}
+	assign, ok := rs.Body.List[0].(*ast.AssignStmt)
+	if !ok || assign.Tok != token.ASSIGN || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+		return nil
+	}
+	call, ok := astutil.Unparen(assign.Rhs[0]).(*ast.CallExpr)
+	if !ok || len(call.Args) != 2 || call.Ellipsis.IsValid() || !isBuiltin(c.pass.TypesInfo, call.Fun, "append") {
+		return nil
+	}
+	keys := c.varOf(assign.Lhs[0])
+	if keys == nil || c.varOf(call.Args[0]) != keys || c.varOf(call.Args[1]) != c.varOf(key) {
+		return nil
+	}
+	return keys
+}
+
+// sortedLater reports whether one of the statements sorts the keys slice.
+func (c *checker) sortedLater(stmts []ast.Stmt, keys types.Object) bool {
+	for _, stmt := range stmts {
+		expr, ok := stmt.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		call, ok := astutil.Unparen(expr.X).(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			continue
+		}
+		fn, ok := typeutil.Callee(c.pass.TypesInfo, call).(*types.Func)
+		if ok && fn.Pkg() != nil && fn.Pkg().Path() == "sort" && sortFuncs[fn.Name()] && c.varOf(call.Args[0]) == keys {
+			return true
+		}
+	}
+	return false
+}
+
+// varOf returns the variable referenced by the expression if it is an identifier.
+func (c *checker) varOf(expr ast.Expr) types.Object {
+	id, ok := astutil.Unparen(expr).(*ast.Ident)
+	if !ok || id.Name == "_" {
+		return nil
+	}
+	return c.pass.TypesInfo.ObjectOf(id)
+}
+
+func (c *checker) referencedFunc(expr ast.Expr) (*types.Func, bool) {
+	var id *ast.Ident
+	switch e := expr.(type) {
+	case *ast.Ident:
+		id = e
+	case *ast.SelectorExpr:
+		id = e.Sel
+	}
+	fn, ok := c.pass.TypesInfo.Uses[id].(*types.Func)
+	return fn, ok
+}
+
+// globalVar returns the package level variable assigned by the expression, if any.
+func (c *checker) globalVar(expr ast.Expr) *types.Var {
+	var id *ast.Ident
+	switch e := astutil.Unparen(expr).(type) {
+	case *ast.Ident:
+		id = e
+	case *ast.SelectorExpr:
+		id = e.Sel
+	default:
+		return nil
+	}
+	v, ok := c.pass.TypesInfo.Uses[id].(*types.Var)
+	if !ok || v.IsField() || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+		return nil
+	}
+	return v
+}
+
+func (c *checker) isIgnored(pos token.Pos) bool {
+	position := c.pass.Fset.Position(pos)
+	lines := c.ignored[position.Filename]
+	return lines[position.Line] || lines[position.Line-1]
+}
+
+func (c *checker) position(pos token.Pos) string {
+	position := c.pass.Fset.Position(pos)
+	return fmt.Sprintf("%v:%v", filepath.Base(position.Filename), position.Line)
+}
+
+func chainReason(callee *types.Func, reason string) string {
+	return fmt.Sprintf("calls %s: %s", callee.Name(), reason)
+}
+
+func forbiddenFunc(fn *types.Func) (string, bool) {
+	if sig, ok := fn.Type().(*types.Signature); !ok || sig.Recv() != nil {
+		// methods of time.Time, rand.Rand and the like are fine
+		return "", false
+	}
+	path := fn.Pkg().Path()
+	if path == "math/rand" {
+		return "workflow.SideEffect", !deterministicRandFuncs[fn.Name()]
+	}
+	replacement, ok := forbiddenFuncs[path][fn.Name()]
+	return replacement, ok
+}
+
+func isWorkflowRegistration(callee types.Object) bool {
+	fn, ok := callee.(*types.Func)
+	if !ok || fn.Pkg() == nil || !isCadencePackage(fn.Pkg().Path()) {
+		return false
+	}
+	if sig := fn.Type().(*types.Signature); sig.Recv() != nil {
+		return fn.Name() == "RegisterWorkflow" || fn.Name() == "RegisterWorkflowWithOptions"
+	}
+	return fn.Pkg().Path() == workflowPkgPath && (fn.Name() == "Register" || fn.Name() == "RegisterWithOptions")
+}
+
+func isMakeChan(info *types.Info, call *ast.CallExpr) bool {
+	if len(call.Args) == 0 || !isBuiltin(info, call.Fun, "make") {
+		return false
+	}
+	_, ok := info.TypeOf(call.Args[0]).Underlying().(*types.Chan)
+	return ok
+}
+
+func isBuiltin(info *types.Info, expr ast.Expr, name string) bool {
+	id, ok := astutil.Unparen(expr).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	b, ok := info.Uses[id].(*types.Builtin)
+	return ok && b.Name() == name
+}
+
+func isBlank(expr ast.Expr) bool {
+	id, ok := expr.(*ast.Ident)
+	return ok && id.Name == "_"
+}
+
+func hasIgnoreDirective(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, comment := range doc.List {
+		if strings.Contains(comment.Text, ignoreDirective) {
+			return true
+		}
+	}
+	return false
+}
+
+func isCadencePackage(path string) bool {
+	return path == cadencePkgPrefix || strings.HasPrefix(path, cadencePkgPrefix+"/")
+}
+
+func isStandardPackage(path string) bool {
+	return !strings.Contains(strings.Split(path, "/")[0], ".")
+}
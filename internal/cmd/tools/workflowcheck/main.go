@@ -0,0 +1,41 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import "golang.org/x/tools/go/analysis/unitchecker"
+
+// command line utility that checks registered workflow functions for non-deterministic code. It runs as a vet tool:
+//
+//  go build -o workflowcheck ./internal/cmd/tools/workflowcheck
+//  go vet -vettool=$(pwd)/workflowcheck ./...
+//
+// Workflows are found through their registration with workflow.Register, workflow.RegisterWithOptions and the
+// RegisterWorkflow methods of workers and test environments. A workflow is reported when it, or any function it
+// calls, starts native goroutines, uses native channels or select, ranges over a map, assigns global variables or
+// calls time, math/rand, crypto/rand and environment functions that have a workflow package equivalent. Functions of
+// other packages are followed as long as they are analyzed too, calls through interfaces are not followed.
+// Ranging over a map only to append its keys to a slice that is sorted later in the same block is not reported.
+//
+// A "//workflowcheck:ignore" comment suppresses the construct on the same or the next line, in the doc comment of a
+// function it marks the whole function as deterministic.
+func main() {
+	unitchecker.Main(Analyzer)
+}
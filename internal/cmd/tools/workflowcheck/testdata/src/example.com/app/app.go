@@ -0,0 +1,52 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package app
+
+import (
+	"example.com/helpers"
+	"example.com/workflows"
+	"go.uber.org/cadence/worker"
+	"go.uber.org/cadence/workflow"
+)
+
+func init() {
+	workflow.Register(workflows.DeterministicWorkflow)
+	workflow.Register(workflows.TimeWorkflow)         // want `workflow TimeWorkflow is not deterministic: call to time.Now, use workflow.Now at workflows.go:\d+`
+	workflow.Register(workflows.GoroutineWorkflow)    // want `workflow GoroutineWorkflow is not deterministic: go statement, use workflow.Go`
+	workflow.Register(workflows.ChannelWorkflow)      // want `workflow ChannelWorkflow is not deterministic: native channel, use workflow.NewChannel`
+	workflow.Register(workflows.SelectWorkflow)       // want `workflow SelectWorkflow is not deterministic: select statement, use workflow.Selector`
+	workflow.Register(workflows.MapWorkflow)          // want `workflow MapWorkflow is not deterministic: iteration over map, sort the keys first`
+	workflow.Register(workflows.LoggingMapWorkflow)   // want `workflow LoggingMapWorkflow is not deterministic: iteration over map, sort the keys first`
+	workflow.Register(workflows.GlobalStateWorkflow)  // want `workflow GlobalStateWorkflow is not deterministic: assignment to global variable counter`
+	workflow.Register(workflows.TransitiveWorkflow)   // want `workflow TransitiveWorkflow is not deterministic: calls step: calls wait: call to time.Sleep, use workflow.Sleep`
+	workflow.Register(workflows.CrossPackageWorkflow) // want `workflow CrossPackageWorkflow is not deterministic: calls Stamp: call to time.Now, use workflow.Now at helpers.go:\d+`
+	workflow.Register(workflows.SuppressedWorkflow)
+	workflow.Register(workflows.SuppressedHelperWorkflow)
+	workflow.RegisterWithOptions(func(ctx workflow.Context) error { // want `workflow function literal is not deterministic: calls Jitter: call to rand.Intn, use workflow.SideEffect`
+		_ = helpers.Jitter()
+		return nil
+	}, workflow.RegisterOptions{Name: "literal"})
+}
+
+func register(w worker.Worker) {
+	w.RegisterWorkflow(workflows.DeterministicWorkflow)
+	w.RegisterWorkflowWithOptions(workflows.TimeWorkflow, workflow.RegisterOptions{Name: "time"}) // want `workflow TimeWorkflow is not deterministic`
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package workflows
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"example.com/helpers"
+	"go.uber.org/cadence/workflow"
+)
+
+var counter int
+
+// DeterministicWorkflow only uses deterministic constructs
+func DeterministicWorkflow(ctx workflow.Context, values map[string]int) (string, error) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	workflow.Go(ctx, func(ctx workflow.Context) {})
+	local := 0
+	local++
+	_ = helpers.Seeded(int64(local))
+	return helpers.Format(workflow.Now(ctx)), nil
+}
+
+// TimeWorkflow reads the wall clock
+func TimeWorkflow(ctx workflow.Context) (time.Time, error) {
+	return time.Now(), nil
+}
+
+// GoroutineWorkflow starts a native goroutine
+func GoroutineWorkflow(ctx workflow.Context) error {
+	go func() {}()
+	return nil
+}
+
+// ChannelWorkflow uses a native channel
+func ChannelWorkflow(ctx workflow.Context) error {
+	ch := make(chan int, 1)
+	ch <- 1
+	return nil
+}
+
+// SelectWorkflow uses a native select
+func SelectWorkflow(ctx workflow.Context) error {
+	select {
+	default:
+	}
+	return nil
+}
+
+// MapWorkflow relies on the map iteration order
+func MapWorkflow(ctx workflow.Context, values map[string]int) ([]string, error) {
+	var keys []string
+	for k := range values {
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// LoggingMapWorkflow sorts the keys, but also logs them in the map iteration order
+func LoggingMapWorkflow(ctx workflow.Context, values map[string]int) ([]string, error) {
+	var keys []string
+	for k := range values {
+		keys = append(keys, k)
+		fmt.Println(k)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// GlobalStateWorkflow modifies global state
+func GlobalStateWorkflow(ctx workflow.Context) (int, error) {
+	counter++
+	return counter, nil
+}
+
+// TransitiveWorkflow calls a non-deterministic helper
+func TransitiveWorkflow(ctx workflow.Context) error {
+	return step()
+}
+
+func step() error {
+	wait()
+	return nil
+}
+
+func wait() {
+	time.Sleep(time.Second)
+}
+
+// CrossPackageWorkflow calls a non-deterministic function of another package
+func CrossPackageWorkflow(ctx workflow.Context) (string, error) {
+	return helpers.Stamp(), nil
+}
+
+// SuppressedWorkflow iterates over a map in an order independent way
+func SuppressedWorkflow(ctx workflow.Context, values map[string]int) (int, error) {
+	sum := 0
+	//workflowcheck:ignore
+	for _, v := range values {
+		sum += v
+	}
+	return sum, nil
+}
+
+// SuppressedHelperWorkflow calls a helper that is marked as deterministic
+func SuppressedHelperWorkflow(ctx workflow.Context, values map[string]int) (int, error) {
+	return count(values), nil
+}
+
+// count only sums up the values
+//
+//workflowcheck:ignore
+func count(values map[string]int) int {
+	sum := 0
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
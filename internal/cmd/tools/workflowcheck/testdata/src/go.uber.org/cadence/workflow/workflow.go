@@ -0,0 +1,50 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package workflow is a stand-in of the cadence workflow package for the analyzer tests.
+package workflow
+
+import "time"
+
+type (
+	// Context stands in for workflow.Context
+	Context interface{}
+
+	// RegisterOptions stands in for workflow.RegisterOptions
+	RegisterOptions struct {
+		Name string
+	}
+)
+
+// Register stands in for workflow.Register
+func Register(workflowFunc interface{}) {}
+
+// RegisterWithOptions stands in for workflow.RegisterWithOptions
+func RegisterWithOptions(workflowFunc interface{}, opts RegisterOptions) {}
+
+// Go stands in for workflow.Go
+func Go(ctx Context, f func(ctx Context)) {
+	go f(ctx)
+}
+
+// Now stands in for workflow.Now
+func Now(ctx Context) time.Time {
+	return time.Now()
+}
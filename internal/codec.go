@@ -0,0 +1,221 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+type (
+	// PayloadCodec is a byte level transformation applied on top of a DataConverter, for example to
+	// encrypt or compress the serialized payloads. Decode must reverse Encode.
+	PayloadCodec interface {
+		// Encode transforms the serialized payload before it is sent over the wire.
+		Encode(data []byte) ([]byte, error)
+		// Decode reverses Encode for a payload received over the wire.
+		Decode(data []byte) ([]byte, error)
+	}
+
+	// AESGCMCodecOptions configures the codec returned by NewAESGCMCodec.
+	AESGCMCodecOptions struct {
+		// Required: ID of the key used to encrypt new payloads, it must be present in Keys.
+		KeyID string
+
+		// Required: Keys available for encryption and decryption by their ID. Each key must be 16, 24 or 32 bytes
+		// long to select AES-128, AES-192 or AES-256. The ID of the key is stored with every payload, so keys can be
+		// rotated by changing KeyID while keeping the previous keys around until the payloads they encrypted expire.
+		Keys map[string][]byte
+	}
+
+	// codecDataConverter applies a chain of PayloadCodecs to the output of the wrapped DataConverter
+	codecDataConverter struct {
+		dataConverter DataConverter
+		codecs        []PayloadCodec
+	}
+
+	aesGCMCodec struct {
+		keyID string
+		aeads map[string]cipher.AEAD
+	}
+
+	gzipCodec struct {
+		minSize int
+	}
+)
+
+const (
+	aesGCMCodecVersion byte = 1
+
+	gzipCodecUncompressed byte = 0
+	gzipCodecCompressed   byte = 1
+)
+
+// NewCodecDataConverter creates a DataConverter that encodes the payloads produced by dataConverter with the given
+// codecs. When encoding the codecs are applied in the given order and when decoding in the reverse order, so
+//  NewCodecDataConverter(dc, NewGzipCodec(1024), aesCodec)
+// compresses payloads before encrypting them. Empty payloads are passed through unchanged.
+// The returned DataConverter has to be configured everywhere the payloads are produced or consumed: on the client,
+// on the workers and through workflow.WithDataConverter inside workflows, exactly as any other DataConverter.
+func NewCodecDataConverter(dataConverter DataConverter, codecs ...PayloadCodec) DataConverter {
+	if dataConverter == nil {
+		dataConverter = getDefaultDataConverter()
+	}
+	return &codecDataConverter{dataConverter: dataConverter, codecs: codecs}
+}
+
+func (dc *codecDataConverter) ToData(value ...interface{}) ([]byte, error) {
+	data, err := dc.dataConverter.ToData(value...)
+	if err != nil || len(data) == 0 {
+		return data, err
+	}
+	for _, codec := range dc.codecs {
+		if data, err = codec.Encode(data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func (dc *codecDataConverter) FromData(input []byte, valuePtr ...interface{}) error {
+	data := input
+	if len(data) > 0 {
+		var err error
+		for i := len(dc.codecs) - 1; i >= 0; i-- {
+			if data, err = dc.codecs[i].Decode(data); err != nil {
+				return err
+			}
+		}
+	}
+	return dc.dataConverter.FromData(data, valuePtr...)
+}
+
+// NewAESGCMCodec creates a PayloadCodec that encrypts payloads with AES in Galois/Counter Mode.
+// Every payload is encrypted with a fresh random nonce and carries the ID of the key used to encrypt it.
+func NewAESGCMCodec(options AESGCMCodecOptions) (PayloadCodec, error) {
+	if _, ok := options.Keys[options.KeyID]; !ok {
+		return nil, fmt.Errorf("encryption key %q is not present in Keys", options.KeyID)
+	}
+	aeads := make(map[string]cipher.AEAD, len(options.Keys))
+	for id, key := range options.Keys {
+		if len(id) > 255 {
+			return nil, fmt.Errorf("key ID %q is longer than 255 bytes", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %v", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		aeads[id] = aead
+	}
+	return &aesGCMCodec{keyID: options.KeyID, aeads: aeads}, nil
+}
+
+// Encode encrypts the payload into version | len(keyID) | keyID | nonce | ciphertext.
+// The header is authenticated as additional data.
+func (c *aesGCMCodec) Encode(data []byte) ([]byte, error) {
+	aead := c.aeads[c.keyID]
+	header := make([]byte, 0, 2+len(c.keyID)+aead.NonceSize())
+	header = append(header, aesGCMCodecVersion, byte(len(c.keyID)))
+	header = append(header, c.keyID...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	result := append(header, nonce...)
+	return aead.Seal(result, nonce, data, header), nil
+}
+
+// Decode decrypts the payload with the key it was encrypted with.
+func (c *aesGCMCodec) Decode(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != aesGCMCodecVersion {
+		return nil, errors.New("payload is not encrypted by the AES-GCM codec")
+	}
+	headerSize := 2 + int(data[1])
+	if len(data) < headerSize {
+		return nil, errors.New("malformed AES-GCM payload")
+	}
+	keyID := string(data[2:headerSize])
+	aead, ok := c.aeads[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown encryption key %q", keyID)
+	}
+	if len(data) < headerSize+aead.NonceSize() {
+		return nil, errors.New("malformed AES-GCM payload")
+	}
+	nonce := data[headerSize : headerSize+aead.NonceSize()]
+	result, err := aead.Open(nil, nonce, data[headerSize+aead.NonceSize():], data[:headerSize])
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt payload with key %q: %v", keyID, err)
+	}
+	return result, nil
+}
+
+// NewGzipCodec creates a PayloadCodec that compresses payloads of at least minSize bytes with gzip.
+// Smaller payloads are only prefixed with a marker, as compression rarely pays off for them.
+func NewGzipCodec(minSize int) PayloadCodec {
+	return &gzipCodec{minSize: minSize}
+}
+
+func (c *gzipCodec) Encode(data []byte) ([]byte, error) {
+	if len(data) < c.minSize {
+		return append([]byte{gzipCodecUncompressed}, data...), nil
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(gzipCodecCompressed)
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *gzipCodec) Decode(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.New("payload is not encoded by the gzip codec")
+	}
+	switch data[0] {
+	case gzipCodecUncompressed:
+		return data[1:], nil
+	case gzipCodecCompressed:
+		r, err := gzip.NewReader(bytes.NewReader(data[1:]))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	default:
+		return nil, errors.New("payload is not encoded by the gzip codec")
+	}
+}
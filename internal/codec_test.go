@@ -0,0 +1,133 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testCodecPayload struct {
+	Name  string
+	Value int
+}
+
+func newTestAESGCMCodec(t *testing.T, keyID string, keys map[string][]byte) PayloadCodec {
+	codec, err := NewAESGCMCodec(AESGCMCodecOptions{KeyID: keyID, Keys: keys})
+	require.NoError(t, err)
+	return codec
+}
+
+func TestCodecDataConverter(t *testing.T) {
+	t.Parallel()
+	keys := map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}
+	dc := NewCodecDataConverter(nil, NewGzipCodec(64), newTestAESGCMCodec(t, "k1", keys))
+
+	input := testCodecPayload{Name: strings.Repeat("cadence", 32), Value: 42}
+	data, err := dc.ToData(input, "second")
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "cadence")
+
+	var result testCodecPayload
+	var second string
+	require.NoError(t, dc.FromData(data, &result, &second))
+	require.Equal(t, input, result)
+	require.Equal(t, "second", second)
+
+	plain, err := getDefaultDataConverter().ToData(input, "second")
+	require.NoError(t, err)
+	require.True(t, len(data) < len(plain), "payload is compressed before encryption")
+
+	// empty payloads are passed through
+	empty, err := dc.ToData([]byte{})
+	require.NoError(t, err)
+	require.Empty(t, empty)
+	var emptyResult []byte
+	require.NoError(t, dc.FromData(empty, &emptyResult))
+	require.Empty(t, emptyResult)
+
+	// workflow arguments go through the same path
+	encoded, err := encodeArgs(dc, []interface{}{1, "two"})
+	require.NoError(t, err)
+	var one int
+	var two string
+	require.NoError(t, decodeArg(dc, encoded, &one))
+	require.Error(t, getDefaultDataConverter().FromData(encoded, &one, &two))
+}
+
+func TestAESGCMCodec_KeyRotation(t *testing.T) {
+	t.Parallel()
+	oldKey := bytes.Repeat([]byte{1}, 16)
+	newKey := bytes.Repeat([]byte{2}, 32)
+
+	oldCodec := newTestAESGCMCodec(t, "old", map[string][]byte{"old": oldKey})
+	encrypted, err := oldCodec.Encode([]byte("payload"))
+	require.NoError(t, err)
+
+	rotated := newTestAESGCMCodec(t, "new", map[string][]byte{"old": oldKey, "new": newKey})
+	decrypted, err := rotated.Decode(encrypted)
+	require.NoError(t, err)
+	require.Equal(t, "payload", string(decrypted))
+
+	reencrypted, err := rotated.Encode(decrypted)
+	require.NoError(t, err)
+	_, err = oldCodec.Decode(reencrypted)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `unknown encryption key "new"`)
+
+	// tampered payloads are rejected
+	tampered := append([]byte{}, encrypted...)
+	tampered[len(tampered)-1] ^= 0xff
+	_, err = rotated.Decode(tampered)
+	require.Error(t, err)
+
+	_, err = NewAESGCMCodec(AESGCMCodecOptions{KeyID: "missing", Keys: map[string][]byte{"old": oldKey}})
+	require.Error(t, err)
+	_, err = NewAESGCMCodec(AESGCMCodecOptions{KeyID: "short", Keys: map[string][]byte{"short": []byte("short")}})
+	require.Error(t, err)
+}
+
+func TestGzipCodec(t *testing.T) {
+	t.Parallel()
+	codec := NewGzipCodec(16)
+
+	small, err := codec.Encode([]byte("small"))
+	require.NoError(t, err)
+	require.Equal(t, append([]byte{gzipCodecUncompressed}, "small"...), small)
+
+	large := bytes.Repeat([]byte("a"), 1024)
+	compressed, err := codec.Encode(large)
+	require.NoError(t, err)
+	require.Equal(t, gzipCodecCompressed, compressed[0])
+	require.True(t, len(compressed) < len(large))
+
+	for _, data := range [][]byte{small, compressed} {
+		decoded, err := codec.Decode(data)
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(decoded, []byte("small")) || bytes.Equal(decoded, large))
+	}
+
+	_, err = codec.Decode([]byte("{}"))
+	require.Error(t, err)
+}
@@ -0,0 +1,302 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"go.uber.org/cadence/internal/common"
+)
+
+type (
+	// PayloadEncoding encodes single values into the payloads written by the composite DataConverter.
+	// The name and version of the encoding are recorded next to every value, so payloads stay readable
+	// as long as an encoding with the same name and version is registered.
+	PayloadEncoding interface {
+		// Name identifies the encoding in the payload envelope.
+		Name() string
+		// Version of the format produced by Encode, recorded in the payload envelope.
+		Version() int
+		// Supports reports whether the value can be encoded, it is used to pick the encoding of each value.
+		Supports(value interface{}) bool
+		// Encode serializes the value.
+		Encode(value interface{}) ([]byte, error)
+		// Decode deserializes data produced by Encode into the value pointer.
+		Decode(data []byte, valuePtr interface{}) error
+	}
+
+	// CompositeDataConverterOptions configures the DataConverter returned by NewCompositeDataConverter.
+	CompositeDataConverterOptions struct {
+		// Optional: Encodings in order of preference. Each value is written with the first encoding that
+		// supports it, and values written with any of the encodings can be read.
+		// default: bytes, thrift, protobuf and json encodings, which mirrors the default DataConverter
+		Encodings []PayloadEncoding
+
+		// Optional: DataConverter used to read payloads written without the envelope, for example by the
+		// workflows that were started before switching to the composite DataConverter.
+		// default: default DataConverter
+		LegacyDataConverter DataConverter
+	}
+
+	// compositeDataConverter writes self-describing payloads with the preferred encodings and reads any registered one
+	compositeDataConverter struct {
+		encodings []PayloadEncoding
+		legacy    DataConverter
+	}
+
+	encodingKey struct {
+		name    string
+		version int
+	}
+
+	// listPayloadEncoding adapts an encoding of value lists to a PayloadEncoding
+	listPayloadEncoding struct {
+		name     string
+		encoding encoding
+		supports func(value interface{}) bool
+	}
+
+	bytesPayloadEncoding struct{}
+)
+
+const (
+	// payloadEnvelopeHeader prefixes payloads written by the composite DataConverter
+	payloadEnvelopeHeader = "\x00envelope"
+
+	payloadEnvelopeVersion byte = 1
+)
+
+// NewCompositeDataConverter creates a DataConverter writing every value in an envelope which records the name
+// and version of the encoding used for it. Reading decodes each value with the registered encoding of the same
+// name and version, and payloads without an envelope with the legacy DataConverter. This allows to migrate
+// encodings gradually: register the new encoding in front of the old one and keep the old one until no open
+// workflow references its payloads.
+func NewCompositeDataConverter(options CompositeDataConverterOptions) DataConverter {
+	dc := &compositeDataConverter{
+		encodings: options.Encodings,
+		legacy:    options.LegacyDataConverter,
+	}
+	if len(dc.encodings) == 0 {
+		dc.encodings = []PayloadEncoding{
+			NewBytesPayloadEncoding(),
+			NewThriftPayloadEncoding(),
+			NewProtoPayloadEncoding(false),
+			NewJSONPayloadEncoding(),
+		}
+	}
+	if dc.legacy == nil {
+		dc.legacy = getDefaultDataConverter()
+	}
+	return dc
+}
+
+func (dc *compositeDataConverter) ToData(values ...interface{}) ([]byte, error) {
+	if len(values) == 0 {
+		// like the default DataConverter, no values are written as an empty payload, so that empty
+		// heartbeat details, inputs and results can be told apart from encoded ones
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	buf.WriteString(payloadEnvelopeHeader)
+	buf.WriteByte(payloadEnvelopeVersion)
+	writeUvarint(&buf, uint64(len(values)))
+	for i, value := range values {
+		encoding := dc.encodingOf(value)
+		if encoding == nil {
+			return nil, fmt.Errorf("no payload encoding supports argument: %d, %v", i, reflect.TypeOf(value))
+		}
+		data, err := encoding.Encode(value)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode argument: %d, %v, with %v encoding: %v",
+				i, reflect.TypeOf(value), encoding.Name(), err)
+		}
+		writeLengthPrefixed(&buf, []byte(encoding.Name()))
+		writeUvarint(&buf, uint64(encoding.Version()))
+		writeLengthPrefixed(&buf, data)
+	}
+	return buf.Bytes(), nil
+}
+
+func (dc *compositeDataConverter) FromData(input []byte, valuePtr ...interface{}) error {
+	if len(input) == 0 {
+		// an empty payload holds no values, the value pointers are left untouched
+		return nil
+	}
+	if !isPayloadEnvelope(input) {
+		return dc.legacy.FromData(input, valuePtr...)
+	}
+	r := bytes.NewReader(input[len(payloadEnvelopeHeader):])
+	if version, _ := r.ReadByte(); version != payloadEnvelopeVersion {
+		return fmt.Errorf("unsupported payload envelope version %v", version)
+	}
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return fmt.Errorf("malformed payload envelope: %v", err)
+	}
+	if uint64(len(valuePtr)) > count {
+		return fmt.Errorf("payload contains %v values, but %v are requested", count, len(valuePtr))
+	}
+	for i, to := range valuePtr {
+		key, data, err := readEnvelopeValue(r)
+		if err != nil {
+			return fmt.Errorf("malformed payload envelope: %v", err)
+		}
+		encoding := dc.encodingFor(key)
+		if encoding == nil {
+			return fmt.Errorf("unable to decode argument: %d, %v, encoding %v version %v is not registered",
+				i, reflect.TypeOf(to), key.name, key.version)
+		}
+		if err := encoding.Decode(data, to); err != nil {
+			return fmt.Errorf("unable to decode argument: %d, %v, with %v encoding: %v",
+				i, reflect.TypeOf(to), encoding.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (dc *compositeDataConverter) encodingOf(value interface{}) PayloadEncoding {
+	for _, encoding := range dc.encodings {
+		if encoding.Supports(value) {
+			return encoding
+		}
+	}
+	return nil
+}
+
+func (dc *compositeDataConverter) encodingFor(key encodingKey) PayloadEncoding {
+	for _, encoding := range dc.encodings {
+		if encoding.Name() == key.name && encoding.Version() == key.version {
+			return encoding
+		}
+	}
+	return nil
+}
+
+// isPayloadEnvelope checks if the data was written by the composite DataConverter
+func isPayloadEnvelope(data []byte) bool {
+	return len(data) > len(payloadEnvelopeHeader) && string(data[:len(payloadEnvelopeHeader)]) == payloadEnvelopeHeader
+}
+
+func readEnvelopeValue(r *bytes.Reader) (encodingKey, []byte, error) {
+	name, err := readLengthPrefixed(r)
+	if err != nil {
+		return encodingKey{}, nil, err
+	}
+	version, err := binary.ReadUvarint(r)
+	if err != nil {
+		return encodingKey{}, nil, err
+	}
+	data, err := readLengthPrefixed(r)
+	if err != nil {
+		return encodingKey{}, nil, err
+	}
+	return encodingKey{name: string(name), version: int(version)}, data, nil
+}
+
+func writeUvarint(buf *bytes.Buffer, value uint64) {
+	var data [binary.MaxVarintLen64]byte
+	buf.Write(data[:binary.PutUvarint(data[:], value)])
+}
+
+// NewJSONPayloadEncoding creates the PayloadEncoding used by the default DataConverter for the values
+// that are neither thrift structures nor protobuf messages. It supports any value.
+func NewJSONPayloadEncoding() PayloadEncoding {
+	return &listPayloadEncoding{
+		name:     "json",
+		encoding: jsonEncoding{},
+		supports: func(interface{}) bool { return true },
+	}
+}
+
+// NewThriftPayloadEncoding creates the PayloadEncoding for thrift structures. Values are decoded into
+// pointers to thrift structure pointers.
+func NewThriftPayloadEncoding() PayloadEncoding {
+	return &listPayloadEncoding{
+		name:     "thrift",
+		encoding: thriftEncoding{},
+		supports: common.IsThriftType,
+	}
+}
+
+// NewProtoPayloadEncoding creates the PayloadEncoding for protobuf messages generated by google.golang.org/protobuf,
+// writing them in binary or json form. Values in either form are decoded.
+func NewProtoPayloadEncoding(json bool) PayloadEncoding {
+	return &listPayloadEncoding{
+		name:     "proto",
+		encoding: protoEncoding{json: json},
+		supports: common.IsProtoType,
+	}
+}
+
+func (e *listPayloadEncoding) Name() string {
+	return e.name
+}
+
+func (e *listPayloadEncoding) Version() int {
+	return 1
+}
+
+func (e *listPayloadEncoding) Supports(value interface{}) bool {
+	return e.supports(value)
+}
+
+func (e *listPayloadEncoding) Encode(value interface{}) ([]byte, error) {
+	return e.encoding.Marshal([]interface{}{value})
+}
+
+func (e *listPayloadEncoding) Decode(data []byte, valuePtr interface{}) error {
+	return e.encoding.Unmarshal(data, []interface{}{valuePtr})
+}
+
+// NewBytesPayloadEncoding creates the PayloadEncoding that writes byte slices as they are.
+func NewBytesPayloadEncoding() PayloadEncoding {
+	return bytesPayloadEncoding{}
+}
+
+func (bytesPayloadEncoding) Name() string {
+	return "bytes"
+}
+
+func (bytesPayloadEncoding) Version() int {
+	return 1
+}
+
+func (bytesPayloadEncoding) Supports(value interface{}) bool {
+	_, ok := value.([]byte)
+	return ok
+}
+
+func (bytesPayloadEncoding) Encode(value interface{}) ([]byte, error) {
+	return value.([]byte), nil
+}
+
+func (bytesPayloadEncoding) Decode(data []byte, valuePtr interface{}) error {
+	to, ok := valuePtr.(*[]byte)
+	if !ok {
+		return errors.New("pointer to byte slice is required")
+	}
+	*to = data
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/internal/common"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// testStringPayloadEncoding writes integers as decimal strings
+type testStringPayloadEncoding struct{}
+
+func (testStringPayloadEncoding) Name() string { return "decimal" }
+
+func (testStringPayloadEncoding) Version() int { return 2 }
+
+func (testStringPayloadEncoding) Supports(value interface{}) bool {
+	_, ok := value.(int)
+	return ok
+}
+
+func (testStringPayloadEncoding) Encode(value interface{}) ([]byte, error) {
+	return []byte(strconv.Itoa(value.(int))), nil
+}
+
+func (testStringPayloadEncoding) Decode(data []byte, valuePtr interface{}) error {
+	v, err := strconv.Atoi(string(data))
+	*valuePtr.(*int) = v
+	return err
+}
+
+func TestCompositeDataConverter(t *testing.T) {
+	t.Parallel()
+	dc := NewCompositeDataConverter(CompositeDataConverterOptions{})
+
+	thriftValue := &shared.WorkflowType{Name: common.StringPtr("type")}
+	data, err := dc.ToData([]byte("raw"), thriftValue, wrapperspb.String("proto"), map[string]int{"json": 1})
+	require.NoError(t, err)
+	require.True(t, isPayloadEnvelope(data))
+
+	var raw []byte
+	var thriftResult *shared.WorkflowType
+	var protoResult *wrapperspb.StringValue
+	var jsonResult map[string]int
+	require.NoError(t, dc.FromData(data, &raw, &thriftResult, &protoResult, &jsonResult))
+	require.Equal(t, "raw", string(raw))
+	require.Equal(t, thriftValue, thriftResult)
+	require.True(t, proto.Equal(wrapperspb.String("proto"), protoResult))
+	require.Equal(t, map[string]int{"json": 1}, jsonResult)
+
+	// reading a prefix of the values is allowed, reading more than written is not
+	require.NoError(t, dc.FromData(data, &raw))
+	var extra string
+	require.Error(t, dc.FromData(data, &raw, &thriftResult, &protoResult, &jsonResult, &extra))
+
+	// payloads written without the envelope are read with the legacy converter
+	legacy, err := getDefaultDataConverter().ToData("legacy", 1)
+	require.NoError(t, err)
+	var s string
+	var i int
+	require.NoError(t, dc.FromData(legacy, &s, &i))
+	require.Equal(t, "legacy", s)
+	require.Equal(t, 1, i)
+}
+
+func TestCompositeDataConverter_Migration(t *testing.T) {
+	t.Parallel()
+	oldDC := NewCompositeDataConverter(CompositeDataConverterOptions{
+		Encodings: []PayloadEncoding{NewJSONPayloadEncoding()},
+	})
+	newDC := NewCompositeDataConverter(CompositeDataConverterOptions{
+		Encodings: []PayloadEncoding{testStringPayloadEncoding{}, NewJSONPayloadEncoding()},
+	})
+
+	oldData, err := oldDC.ToData(42, "text")
+	require.NoError(t, err)
+	newData, err := newDC.ToData(42, "text")
+	require.NoError(t, err)
+	require.Contains(t, string(newData), "decimal")
+
+	// the new converter reads what the old one wrote
+	for _, data := range [][]byte{oldData, newData} {
+		var i int
+		var s string
+		require.NoError(t, newDC.FromData(data, &i, &s))
+		require.Equal(t, 42, i)
+		require.Equal(t, "text", s)
+	}
+
+	// the old converter does not know the new encoding
+	var i int
+	err = oldDC.FromData(newData, &i)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "encoding decimal version 2 is not registered")
+}
+
+func TestCompositeDataConverter_NoValues(t *testing.T) {
+	t.Parallel()
+	dc := NewCompositeDataConverter(CompositeDataConverterOptions{})
+
+	// no values are written as an empty payload, like the default converter does
+	data, err := dc.ToData()
+	require.NoError(t, err)
+	require.Empty(t, data)
+
+	s := "unchanged"
+	require.NoError(t, dc.FromData(data, &s))
+	require.Equal(t, "unchanged", s)
+	require.NoError(t, dc.FromData(nil))
+}
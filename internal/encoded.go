@@ -62,8 +62,18 @@ type (
 		FromData(input []byte, valuePtr ...interface{}) error
 	}
 
-	// defaultDataConverter uses thrift encoder/decoder when possible, for everything else use json.
-	defaultDataConverter struct{}
+	// DefaultDataConverterOptions configures the DataConverter returned by NewDefaultDataConverter.
+	DefaultDataConverterOptions struct {
+		// Optional: Encodes protobuf messages in their json form instead of the binary wire format.
+		// Payloads in either form are decoded regardless of this option.
+		// default: false
+		ProtoJSON bool
+	}
+
+	// defaultDataConverter uses thrift or protobuf encoder/decoder when possible, for everything else use json.
+	defaultDataConverter struct {
+		protoJSON bool
+	}
 )
 
 var defaultJSONDataConverter = &defaultDataConverter{}
@@ -76,6 +86,14 @@ func getDefaultDataConverter() DataConverter {
 	return defaultJSONDataConverter
 }
 
+// NewDefaultDataConverter creates the default DataConverter with the given options.
+// Like the default DataConverter it encodes thrift structures with thrift, protobuf messages generated by
+// google.golang.org/protobuf with protobuf and everything else with json. A single call uses protobuf only when
+// all the values are protobuf messages.
+func NewDefaultDataConverter(options DefaultDataConverterOptions) DataConverter {
+	return &defaultDataConverter{protoJSON: options.ProtoJSON}
+}
+
 func (dc *defaultDataConverter) ToData(r ...interface{}) ([]byte, error) {
 	if len(r) == 1 && util.IsTypeByteSlice(reflect.TypeOf(r[0])) {
 		return r[0].([]byte), nil
@@ -84,6 +102,8 @@ func (dc *defaultDataConverter) ToData(r ...interface{}) ([]byte, error) {
 	var encoder encoding
 	if common.IsUseThriftEncoding(r) {
 		encoder = &thriftEncoding{}
+	} else if common.IsUseProtoEncoding(r) {
+		encoder = &protoEncoding{json: dc.protoJSON}
 	} else {
 		encoder = &jsonEncoding{}
 	}
@@ -102,7 +122,9 @@ func (dc *defaultDataConverter) FromData(data []byte, to ...interface{}) error {
 	}
 
 	var encoder encoding
-	if common.IsUseThriftDecoding(to) {
+	if isProtoEncoded(data) {
+		encoder = &protoEncoding{}
+	} else if common.IsUseThriftDecoding(to) {
 		encoder = &thriftEncoding{}
 	} else {
 		encoder = &jsonEncoding{}
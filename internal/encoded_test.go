@@ -25,6 +25,9 @@ import (
 	"encoding/gob"
 	"fmt"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 	"reflect"
 	"testing"
 )
@@ -127,3 +130,44 @@ func TestDecodeArg(t *testing.T) {
 	require.NoError(t, err)
 	require.Error(t, decodeArg(dc, b, &r))
 }
+
+func TestProtoDataConverter(t *testing.T) {
+	t.Parallel()
+	binaryDC := getDefaultDataConverter()
+	jsonDC := NewDefaultDataConverter(DefaultDataConverterOptions{ProtoJSON: true})
+
+	for name, dc := range map[string]DataConverter{"binary": binaryDC, "json": jsonDC} {
+		dc := dc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			str := wrapperspb.String("cadence")
+			ts := &timestamppb.Timestamp{Seconds: 1234, Nanos: 5678}
+			data, err := dc.ToData(str, ts)
+			require.NoError(t, err)
+			require.True(t, isProtoEncoded(data))
+
+			// payloads decode with either converter, into message pointers or pointers to message pointers
+			for _, decoder := range []DataConverter{binaryDC, jsonDC} {
+				var strResult *wrapperspb.StringValue
+				tsResult := &timestamppb.Timestamp{}
+				require.NoError(t, decoder.FromData(data, &strResult, tsResult))
+				require.True(t, proto.Equal(str, strResult))
+				require.True(t, proto.Equal(ts, tsResult))
+			}
+
+			var wrongType *wrapperspb.Int64Value
+			require.Error(t, dc.FromData(data, &wrongType))
+			var notProto string
+			require.Error(t, dc.FromData(data, &notProto))
+		})
+	}
+
+	data, err := jsonDC.ToData(wrapperspb.String("cadence"))
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"cadence"`)
+
+	// values are encoded with json unless all of them are protobuf messages
+	data, err = binaryDC.ToData(wrapperspb.String("cadence"), "plain")
+	require.NoError(t, err)
+	require.False(t, isProtoEncoded(data))
+}
@@ -22,13 +22,17 @@ package internal
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
 
 	"github.com/apache/thrift/lib/go/thrift"
 	"go.uber.org/cadence/internal/common"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
 // encoding is capable of encoding and decoding objects
@@ -108,3 +112,130 @@ func (g thriftEncoding) Unmarshal(data []byte, objs []interface{}) error {
 
 	return nil
 }
+
+const (
+	// protoEncodingHeader prefixes payloads produced by protoEncoding. Neither json nor thrift payloads start with it.
+	protoEncodingHeader = "\x00proto"
+
+	protoEncodingBinary byte = 'b'
+	protoEncodingJSON   byte = 'j'
+)
+
+// protoEncoding encapsulates protobuf serializer/de-serializer.
+// The payload is self-describing: the header records whether messages are in binary or json form, and every
+// message is stored with its full name followed by its length prefixed data.
+type protoEncoding struct {
+	json bool
+}
+
+// isProtoEncoded checks if the data was produced by protoEncoding
+func isProtoEncoded(data []byte) bool {
+	return len(data) > len(protoEncodingHeader) && string(data[:len(protoEncodingHeader)]) == protoEncodingHeader
+}
+
+// Marshal encodes an array of protobuf messages into bytes
+func (g protoEncoding) Marshal(objs []interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(protoEncodingHeader)
+	if g.json {
+		buf.WriteByte(protoEncodingJSON)
+	} else {
+		buf.WriteByte(protoEncodingBinary)
+	}
+	for i, obj := range objs {
+		m, ok := obj.(proto.Message)
+		if !ok {
+			return nil, fmt.Errorf("proto.Message type is required for %v argument", i+1)
+		}
+		var data []byte
+		var err error
+		if g.json {
+			data, err = protojson.Marshal(m)
+		} else {
+			data, err = proto.Marshal(m)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode argument: %d, %v, with protobuf error: %v", i, reflect.TypeOf(obj), err)
+		}
+		writeLengthPrefixed(&buf, []byte(m.ProtoReflect().Descriptor().FullName()))
+		writeLengthPrefixed(&buf, data)
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes a byte array into the passed in protobuf messages
+func (g protoEncoding) Unmarshal(data []byte, objs []interface{}) error {
+	if !isProtoEncoded(data) {
+		return errors.New("payload is not encoded by the protobuf encoding")
+	}
+	format := data[len(protoEncodingHeader)]
+	if format != protoEncodingBinary && format != protoEncodingJSON {
+		return fmt.Errorf("unknown protobuf payload format %q", format)
+	}
+	r := bytes.NewReader(data[len(protoEncodingHeader)+1:])
+	for i, obj := range objs {
+		name, err := readLengthPrefixed(r)
+		if err != nil {
+			return fmt.Errorf("unable to decode argument: %d, %v, with protobuf error: %v", i, reflect.TypeOf(obj), err)
+		}
+		value, err := readLengthPrefixed(r)
+		if err != nil {
+			return fmt.Errorf("unable to decode argument: %d, %v, with protobuf error: %v", i, reflect.TypeOf(obj), err)
+		}
+
+		m, assign, err := newProtoTarget(obj)
+		if err != nil {
+			return fmt.Errorf("%v for %v argument", err, i+1)
+		}
+		if fullName := string(m.ProtoReflect().Descriptor().FullName()); fullName != string(name) {
+			return fmt.Errorf("unable to decode argument: %d, %v, payload contains message %v", i, reflect.TypeOf(obj), string(name))
+		}
+		if format == protoEncodingJSON {
+			err = protojson.Unmarshal(value, m)
+		} else {
+			err = proto.Unmarshal(value, m)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to decode argument: %d, %v, with protobuf error: %v", i, reflect.TypeOf(obj), err)
+		}
+		assign()
+	}
+	return nil
+}
+
+// newProtoTarget returns the message to decode into for a pointer to a message or a pointer to a message pointer.
+// The returned function stores the decoded message into the target.
+func newProtoTarget(obj interface{}) (proto.Message, func(), error) {
+	if m, ok := obj.(proto.Message); ok && !reflect.ValueOf(obj).IsNil() {
+		return m, func() {}, nil
+	}
+	rVal := reflect.ValueOf(obj)
+	if rVal.Kind() != reflect.Ptr || rVal.IsNil() || rVal.Elem().Kind() != reflect.Ptr {
+		return nil, nil, errors.New("pointer to proto.Message type is required")
+	}
+	value := reflect.New(rVal.Elem().Type().Elem())
+	m, ok := value.Interface().(proto.Message)
+	if !ok {
+		return nil, nil, errors.New("pointer to proto.Message type is required")
+	}
+	return m, func() { rVal.Elem().Set(value) }, nil
+}
+
+func writeLengthPrefixed(buf *bytes.Buffer, data []byte) {
+	var length [binary.MaxVarintLen64]byte
+	buf.Write(length[:binary.PutUvarint(length[:], uint64(len(data)))])
+	buf.Write(data)
+}
+
+func readLengthPrefixed(r *bytes.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if length > uint64(r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	data := make([]byte, length)
+	_, err = io.ReadFull(r, data)
+	return data, err
+}
@@ -129,10 +129,20 @@ type (
 		wfn    interface{}
 		args   []interface{}
 		params *executeWorkflowParams
+		// memo and searchAttributes of the next run, the ones of the current run are used when nil
+		memo             *shared.Memo
+		searchAttributes *shared.SearchAttributes
+		retryPolicy      *shared.RetryPolicy
 	}
 
 	// UnknownExternalWorkflowExecutionError can be returned when external workflow doesn't exist
 	UnknownExternalWorkflowExecutionError struct{}
+
+	// UpdateRejectedError is returned from Client.UpdateWorkflow when the workflow rejected the update,
+	// either because its validator returned an error or because no handler is registered for it.
+	UpdateRejectedError struct {
+		cause error
+	}
 )
 
 const (
@@ -244,6 +254,74 @@ func NewContinueAsNewError(ctx Context, wfn interface{}, args ...interface{}) *C
 	return &ContinueAsNewError{wfn: wfn, args: args, params: params}
 }
 
+// NewContinueAsNewErrorWithOptions creates ContinueAsNewError instance like NewContinueAsNewError, with the task list,
+// timeouts, retry policy, memo and search attributes of the new execution taken from options. Task list and timeouts
+// that are not set in options are inherited from ctx. Memo and search attributes of options replace the ones of the
+// current execution, so the new execution starts without them if they are not set.
+// If the memo or search attributes of options cannot be serialized, the serialization error is returned instead
+// of a *ContinueAsNewError, so the workflow fails with it when it is returned from the workflow function.
+func NewContinueAsNewErrorWithOptions(ctx Context, options ContinueAsNewOptions, wfn interface{}, args ...interface{}) error {
+	err, serializeErr := newContinueAsNewErrorWithOptions(ctx, options, wfn, args...)
+	if serializeErr != nil {
+		return serializeErr
+	}
+	if err.memo == nil {
+		err.memo = &shared.Memo{}
+	}
+	if err.searchAttributes == nil {
+		err.searchAttributes = &shared.SearchAttributes{}
+	}
+	return err
+}
+
+// NewContinueAsNewErrorWithCarryOver creates ContinueAsNewError instance like NewContinueAsNewErrorWithOptions,
+// but the new execution keeps the memo and search attributes of the current execution, including the ones set
+// through UpsertSearchAttributes. Memo and search attributes of options are merged on top of them.
+// Like NewContinueAsNewErrorWithOptions, it returns the serialization error of the memo or search attributes
+// of options if there is one.
+func NewContinueAsNewErrorWithCarryOver(ctx Context, options ContinueAsNewOptions, wfn interface{}, args ...interface{}) error {
+	err, serializeErr := newContinueAsNewErrorWithOptions(ctx, options, wfn, args...)
+	if serializeErr != nil {
+		return serializeErr
+	}
+	info := GetWorkflowInfo(ctx)
+	memo, searchAttributes := copyMemo(info.Memo), copySearchAttributes(info.SearchAttributes)
+	if err.memo != nil {
+		memo = mergeMemo(memo, err.memo)
+	}
+	if err.searchAttributes != nil {
+		searchAttributes = mergeSearchAttributes(searchAttributes, err.searchAttributes)
+	}
+	err.memo, err.searchAttributes = memo, searchAttributes
+	return err
+}
+
+func newContinueAsNewErrorWithOptions(ctx Context, options ContinueAsNewOptions, wfn interface{}, args ...interface{}) (*ContinueAsNewError, error) {
+	if options.TaskList != "" {
+		ctx = WithWorkflowTaskList(ctx, options.TaskList)
+	}
+	if options.ExecutionStartToCloseTimeout > 0 {
+		ctx = WithExecutionStartToCloseTimeout(ctx, options.ExecutionStartToCloseTimeout)
+	}
+	if options.DecisionTaskStartToCloseTimeout > 0 {
+		ctx = WithWorkflowTaskStartToCloseTimeout(ctx, options.DecisionTaskStartToCloseTimeout)
+	}
+	err := NewContinueAsNewError(ctx, wfn, args...)
+
+	memo, memoErr := getWorkflowMemo(options.Memo, err.params.dataConverter)
+	if memoErr != nil {
+		return nil, memoErr
+	}
+	searchAttributes, searchAttributesErr := serializeSearchAttributes(options.SearchAttributes)
+	if searchAttributesErr != nil {
+		return nil, searchAttributesErr
+	}
+	err.memo = memo
+	err.searchAttributes = searchAttributes
+	err.retryPolicy = convertRetryPolicy(options.RetryPolicy)
+	return err, nil
+}
+
 // Error from error interface
 func (e *CustomError) Error() string {
 	return e.reason
@@ -375,3 +453,13 @@ func newUnknownExternalWorkflowExecutionError() *UnknownExternalWorkflowExecutio
 func (e *UnknownExternalWorkflowExecutionError) Error() string {
 	return "UnknownExternalWorkflowExecution"
 }
+
+// Error from error interface
+func (e *UpdateRejectedError) Error() string {
+	return fmt.Sprintf("update rejected: %v", e.cause)
+}
+
+// Cause returns the error the update was rejected with
+func (e *UpdateRejectedError) Cause() error {
+	return e.cause
+}
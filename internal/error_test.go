@@ -23,7 +23,9 @@ package internal
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/cadence/.gen/go/shared"
@@ -118,7 +120,7 @@ func testTimeoutErrorDetails(t *testing.T, timeoutType shared.TimeoutType) {
 	activityID := "activityID"
 	context.decisionsHelper.scheduledEventIDToActivityID[5] = activityID
 	di := h.newActivityDecisionStateMachine(
-		&shared.ScheduleActivityTaskDecisionAttributes{ActivityId: common.StringPtr(activityID)})
+		&shared.ScheduleActivityTaskDecisionAttributes{ActivityId: common.StringPtr(activityID)}, CancellationTypeTryCancel)
 	di.state = decisionStateInitiated
 	di.setData(&scheduledActivity{
 		callback: func(r []byte, e error) {
@@ -460,3 +462,108 @@ func Test_ContinueAsNewError(t *testing.T) {
 	require.Equal(t, a2, stringArg)
 	require.Equal(t, header, continueAsNewErr.params.header)
 }
+
+func getSortedKeys(fields map[string][]byte) []string {
+	var keys []string
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func Test_ContinueAsNewErrorWithOptions(t *testing.T) {
+	continueAsNewWfName := "continueAsNewWithOptionsWorkflowFn"
+	continueAsNewWorkflowFn := func(ctx Context) error {
+		return NewContinueAsNewErrorWithOptions(ctx, ContinueAsNewOptions{
+			TaskList:                        "next-tasklist",
+			DecisionTaskStartToCloseTimeout: time.Minute,
+			RetryPolicy:                     &RetryPolicy{InitialInterval: time.Second, MaximumAttempts: 3},
+			Memo:                            map[string]interface{}{"next": "memo"},
+		}, continueAsNewWfName)
+	}
+
+	s := &WorkflowTestSuite{}
+	wfEnv := s.NewTestWorkflowEnvironment()
+	wfEnv.RegisterWorkflowWithOptions(continueAsNewWorkflowFn, RegisterWorkflowOptions{
+		Name: continueAsNewWfName,
+	})
+	require.NoError(t, wfEnv.SetMemoOnStart(map[string]interface{}{"current": "memo"}))
+	wfEnv.ExecuteWorkflow(continueAsNewWorkflowFn)
+	err := wfEnv.GetWorkflowError()
+
+	require.Error(t, err)
+	continueAsNewErr, ok := err.(*ContinueAsNewError)
+	require.True(t, ok)
+	require.Equal(t, continueAsNewWfName, continueAsNewErr.WorkflowType().Name)
+	require.Equal(t, "next-tasklist", *continueAsNewErr.params.taskListName)
+	require.Equal(t, int32(60), *continueAsNewErr.params.taskStartToCloseTimeoutSeconds)
+	require.Equal(t, int32(1), *continueAsNewErr.params.executionStartToCloseTimeoutSeconds)
+	require.Equal(t, int32(3), continueAsNewErr.retryPolicy.GetMaximumAttempts())
+	require.Equal(t, []string{"next"}, getSortedKeys(continueAsNewErr.memo.Fields))
+	require.NotNil(t, continueAsNewErr.searchAttributes)
+	require.Empty(t, continueAsNewErr.searchAttributes.IndexedFields)
+}
+
+func Test_ContinueAsNewErrorWithOptions_SerializationError(t *testing.T) {
+	continueAsNewWfName := "continueAsNewWithBadOptionsWorkflowFn"
+	continueAsNewWorkflowFn := func(ctx Context) error {
+		err := NewContinueAsNewErrorWithOptions(ctx, ContinueAsNewOptions{
+			SearchAttributes: map[string]interface{}{"CustomKeywordField": make(chan int)},
+		}, continueAsNewWfName)
+		if _, ok := err.(*ContinueAsNewError); ok {
+			return errors.New("unexpected ContinueAsNewError")
+		}
+		err = NewContinueAsNewErrorWithCarryOver(ctx, ContinueAsNewOptions{
+			Memo: map[string]interface{}{"status": func() {}},
+		}, continueAsNewWfName)
+		if _, ok := err.(*ContinueAsNewError); ok {
+			return errors.New("unexpected ContinueAsNewError")
+		}
+		return err
+	}
+
+	s := &WorkflowTestSuite{}
+	wfEnv := s.NewTestWorkflowEnvironment()
+	wfEnv.RegisterWorkflowWithOptions(continueAsNewWorkflowFn, RegisterWorkflowOptions{
+		Name: continueAsNewWfName,
+	})
+	wfEnv.ExecuteWorkflow(continueAsNewWorkflowFn)
+	err := wfEnv.GetWorkflowError()
+
+	require.Error(t, err)
+	_, ok := err.(*ContinueAsNewError)
+	require.False(t, ok)
+	require.Contains(t, err.Error(), "unsupported type")
+}
+
+func Test_ContinueAsNewErrorWithCarryOver(t *testing.T) {
+	continueAsNewWfName := "continueAsNewWithCarryOverWorkflowFn"
+	continueAsNewWorkflowFn := func(ctx Context) error {
+		if err := UpsertSearchAttributes(ctx, map[string]interface{}{"CustomKeywordField": "current"}); err != nil {
+			return err
+		}
+		return NewContinueAsNewErrorWithCarryOver(ctx, ContinueAsNewOptions{
+			Memo: map[string]interface{}{"status": "continued"},
+		}, continueAsNewWfName)
+	}
+
+	s := &WorkflowTestSuite{}
+	wfEnv := s.NewTestWorkflowEnvironment()
+	wfEnv.RegisterWorkflowWithOptions(continueAsNewWorkflowFn, RegisterWorkflowOptions{
+		Name: continueAsNewWfName,
+	})
+	require.NoError(t, wfEnv.SetMemoOnStart(map[string]interface{}{"current": "memo", "status": "running"}))
+	wfEnv.ExecuteWorkflow(continueAsNewWorkflowFn)
+	err := wfEnv.GetWorkflowError()
+
+	require.Error(t, err)
+	continueAsNewErr, ok := err.(*ContinueAsNewError)
+	require.True(t, ok)
+	require.Nil(t, continueAsNewErr.retryPolicy)
+	require.Equal(t, []string{"current", "status"}, getSortedKeys(continueAsNewErr.memo.Fields))
+	var status string
+	require.NoError(t, getDefaultDataConverter().FromData(continueAsNewErr.memo.Fields["status"], &status))
+	require.Equal(t, "continued", status)
+	require.Equal(t, []string{"CustomKeywordField"}, getSortedKeys(continueAsNewErr.searchAttributes.IndexedFields))
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// AllOf returns a Future that becomes ready when all the futures are ready. Its Get returns the error of the first
+// failed future in the order the futures are passed, the values must be obtained from the futures themselves.
+func AllOf(ctx Context, futures ...Future) Future {
+	future, settable := NewFuture(ctx)
+	GoNamed(ctx, "all-of", func(ctx Context) {
+		var firstErr error
+		for _, f := range futures {
+			if err := f.Get(ctx, nil); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		settable.SetError(firstErr)
+	})
+	return future
+}
+
+// AllOfOrFirstError returns a Future that becomes ready when all the futures complete successfully or as soon as
+// one of them fails. On the first failure cancel is called, so the operations still running can be canceled by
+// passing the CancelFunc of the context they were started with, and the returned Future fails with that error.
+// The cancel parameter can be nil.
+func AllOfOrFirstError(ctx Context, cancel CancelFunc, futures ...Future) Future {
+	future, settable := NewFuture(ctx)
+	GoNamed(ctx, "all-of-or-first-error", func(ctx Context) {
+		var firstErr error
+		selector := NewNamedSelector(ctx, "all-of-or-first-error")
+		for _, f := range futures {
+			selector.AddFuture(f, func(f Future) {
+				if err := f.Get(ctx, nil); err != nil && firstErr == nil {
+					firstErr = err
+				}
+			})
+		}
+		for range futures {
+			selector.Select(ctx)
+			if firstErr != nil {
+				if cancel != nil {
+					cancel()
+				}
+				break
+			}
+		}
+		settable.SetError(firstErr)
+	})
+	return future
+}
+
+// AnyOf returns a Future that becomes ready when any of the futures is ready. Its value is the index of the first
+// ready future, its result must be obtained from that future. When several futures are ready at the same time the
+// lowest index is returned.
+func AnyOf(ctx Context, futures ...Future) Future {
+	future, settable := NewFuture(ctx)
+	if len(futures) == 0 {
+		settable.SetError(errors.New("AnyOf requires at least one future"))
+		return future
+	}
+	GoNamed(ctx, "any-of", func(ctx Context) {
+		selector := NewNamedSelector(ctx, "any-of")
+		for i, f := range futures {
+			i := i
+			selector.AddFuture(f, func(f Future) {
+				settable.SetValue(i)
+			})
+		}
+		selector.Select(ctx)
+	})
+	return future
+}
+
+// ParallelMap calls fn for every element of the items slice, running at most concurrency calls at once. A
+// concurrency lower than one means no limit. The fn must be a function taking workflow.Context and a parameter of the
+// items element type and returning either error or a result and error. When resultsPtr is not nil it must point to
+// a slice the results are assigned to, in the order of items. On the first error no more calls are started, the
+// context passed to the running ones is canceled and the error is returned once they complete.
+func ParallelMap(ctx Context, items interface{}, concurrency int, fn interface{}, resultsPtr interface{}) error {
+	itemsValue := reflect.ValueOf(items)
+	if itemsValue.Kind() != reflect.Slice {
+		return fmt.Errorf("items must be slice but was %v", itemsValue.Kind())
+	}
+	fnValue := reflect.ValueOf(fn)
+	if err := validateParallelMapFn(fnValue.Type(), itemsValue.Type().Elem()); err != nil {
+		return err
+	}
+	var results reflect.Value
+	if resultsPtr != nil {
+		resultsType := reflect.TypeOf(resultsPtr)
+		if resultsType.Kind() != reflect.Ptr || resultsType.Elem().Kind() != reflect.Slice {
+			return errors.New("resultsPtr must be pointer to slice")
+		}
+		if fnValue.Type().NumOut() != 2 || !fnValue.Type().Out(0).AssignableTo(resultsType.Elem().Elem()) {
+			return fmt.Errorf("result of fn is not assignable to %v", resultsType.Elem().Elem())
+		}
+		results = reflect.MakeSlice(resultsType.Elem(), itemsValue.Len(), itemsValue.Len())
+	}
+
+	n := itemsValue.Len()
+	if concurrency < 1 || concurrency > n {
+		concurrency = n
+	}
+	if n == 0 {
+		if results.IsValid() {
+			reflect.ValueOf(resultsPtr).Elem().Set(results)
+		}
+		return nil
+	}
+
+	ctx, cancel := WithCancel(ctx)
+	defer cancel()
+	semaphore := NewSemaphore(ctx, int64(concurrency))
+	waitGroup := NewWaitGroup(ctx)
+	var firstErr error
+	for i := 0; i < n; i++ {
+		if err := semaphore.Acquire(ctx, 1); err != nil {
+			// the context is canceled either by the caller or by a failed call
+			if firstErr == nil {
+				firstErr = err
+			}
+			break
+		}
+		if firstErr != nil {
+			break
+		}
+		i := i
+		waitGroup.Add(1)
+		GoNamed(ctx, fmt.Sprintf("parallel-map-%v", i), func(ctx Context) {
+			defer waitGroup.Done()
+			defer semaphore.Release(1)
+			ret := fnValue.Call([]reflect.Value{reflect.ValueOf(ctx), itemsValue.Index(i)})
+			if err, _ := ret[len(ret)-1].Interface().(error); err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			if results.IsValid() {
+				results.Index(i).Set(ret[0])
+			}
+		})
+	}
+	waitGroup.Wait(ctx)
+	if firstErr != nil {
+		return firstErr
+	}
+	if results.IsValid() {
+		reflect.ValueOf(resultsPtr).Elem().Set(results)
+	}
+	return nil
+}
+
+func validateParallelMapFn(fnType reflect.Type, itemType reflect.Type) error {
+	if fnType == nil || fnType.Kind() != reflect.Func {
+		return fmt.Errorf("fn must be function but was %v", fnType)
+	}
+	if fnType.NumIn() != 2 || !isWorkflowContext(fnType.In(0)) {
+		return errors.New("fn must accept workflow.Context and an element of items")
+	}
+	if !itemType.AssignableTo(fnType.In(1)) {
+		return fmt.Errorf("items element type %v is not assignable to %v", itemType, fnType.In(1))
+	}
+	if fnType.NumOut() < 1 || fnType.NumOut() > 2 || !isError(fnType.Out(fnType.NumOut()-1)) {
+		return errors.New("fn must return either error or a result and error")
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type FutureCombinatorsTestSuite struct {
+	*require.Assertions
+	suite.Suite
+	WorkflowTestSuite
+}
+
+func TestFutureCombinatorsTestSuite(t *testing.T) {
+	suite.Run(t, new(FutureCombinatorsTestSuite))
+}
+
+func (s *FutureCombinatorsTestSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+}
+
+// newFailingFuture returns a future failing with err after the delay
+func newFailingFuture(ctx Context, delay time.Duration, err error) Future {
+	future, settable := NewFuture(ctx)
+	Go(ctx, func(ctx Context) {
+		_ = Sleep(ctx, delay)
+		settable.SetError(err)
+	})
+	return future
+}
+
+func (s *FutureCombinatorsTestSuite) TestAllOf() {
+	workflowFn := func(ctx Context) (time.Duration, error) {
+		start := Now(ctx)
+		err := AllOf(ctx,
+			NewTimer(ctx, time.Minute),
+			newFailingFuture(ctx, time.Second, errors.New("first")),
+			NewTimer(ctx, time.Hour),
+			newFailingFuture(ctx, time.Millisecond, errors.New("second")),
+		).Get(ctx, nil)
+		if err == nil || err.Error() != "first" {
+			return 0, errors.New("expected the error of the first failed future")
+		}
+		return Now(ctx).Sub(start), nil
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var elapsed time.Duration
+	s.NoError(env.GetWorkflowResult(&elapsed))
+	s.Equal(time.Hour, elapsed)
+}
+
+func (s *FutureCombinatorsTestSuite) TestAllOfOrFirstError() {
+	workflowFn := func(ctx Context) error {
+		timerCtx, cancel := WithCancel(ctx)
+		timer := NewTimer(timerCtx, time.Hour)
+		err := AllOfOrFirstError(ctx, cancel,
+			NewTimer(ctx, time.Second),
+			timer,
+			newFailingFuture(ctx, time.Minute, errors.New("failed")),
+		).Get(ctx, nil)
+		if err == nil || err.Error() != "failed" {
+			return errors.New("expected the error of the failed future")
+		}
+		if _, ok := timer.Get(ctx, nil).(*CanceledError); !ok {
+			return errors.New("expected the timer to be canceled")
+		}
+		return nil
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+}
+
+func (s *FutureCombinatorsTestSuite) TestAllOfOrFirstError_Success() {
+	workflowFn := func(ctx Context) error {
+		return AllOfOrFirstError(ctx, nil, NewTimer(ctx, time.Second), NewTimer(ctx, time.Minute)).Get(ctx, nil)
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+}
+
+func (s *FutureCombinatorsTestSuite) TestAnyOf() {
+	workflowFn := func(ctx Context) (int, error) {
+		var index int
+		err := AnyOf(ctx, NewTimer(ctx, time.Hour), NewTimer(ctx, time.Minute)).Get(ctx, &index)
+		return index, err
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var index int
+	s.NoError(env.GetWorkflowResult(&index))
+	s.Equal(1, index)
+}
+
+func (s *FutureCombinatorsTestSuite) TestParallelMap() {
+	workflowFn := func(ctx Context) ([]int, error) {
+		running, maxRunning := 0, 0
+		var results []int
+		err := ParallelMap(ctx, []int{1, 2, 3, 4, 5}, 2, func(ctx Context, item int) (int, error) {
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			_ = Sleep(ctx, time.Duration(6-item)*time.Minute)
+			running--
+			return item * 10, nil
+		}, &results)
+		if err != nil {
+			return nil, err
+		}
+		if maxRunning != 2 {
+			return nil, errors.New("concurrency limit is not respected")
+		}
+		return results, nil
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var results []int
+	s.NoError(env.GetWorkflowResult(&results))
+	s.Equal([]int{10, 20, 30, 40, 50}, results)
+}
+
+func (s *FutureCombinatorsTestSuite) TestParallelMap_Error() {
+	workflowFn := func(ctx Context) ([]string, error) {
+		var started []string
+		err := ParallelMap(ctx, []string{"a", "b", "c", "d"}, 2, func(ctx Context, item string) error {
+			started = append(started, item)
+			if item == "b" {
+				return errors.New("failed " + item)
+			}
+			return Sleep(ctx, time.Hour)
+		}, nil)
+		if err == nil || err.Error() != "failed b" {
+			return nil, errors.New("expected the error of the failed call")
+		}
+		return started, nil
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var started []string
+	s.NoError(env.GetWorkflowResult(&started))
+	s.Equal([]string{"a", "b"}, started)
+}
+
+func (s *FutureCombinatorsTestSuite) TestParallelMap_InvalidFn() {
+	workflowFn := func(ctx Context) error {
+		return ParallelMap(ctx, []string{"a"}, 1, func(ctx Context, item int) error { return nil }, nil)
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.Error(env.GetWorkflowError())
+}
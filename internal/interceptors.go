@@ -22,9 +22,11 @@
 package internal
 
 import (
+	"context"
 	"time"
 
 	"github.com/uber-go/tally"
+	s "go.uber.org/cadence/.gen/go/shared"
 	"go.uber.org/zap"
 )
 
@@ -65,6 +67,9 @@ type WorkflowInterceptor interface {
 	MutableSideEffect(ctx Context, id string, f func(ctx Context) interface{}, equals func(a, b interface{}) bool) Value
 	GetVersion(ctx Context, changeID string, minSupported, maxSupported Version) Version
 	SetQueryHandler(ctx Context, queryType string, handler interface{}) error
+	SetUpdateHandler(ctx Context, updateName string, handler interface{}, options UpdateHandlerOptions) error
+	SetSignalHandler(ctx Context, signalName string, handler interface{}) error
+	GetUnhandledSignalNames(ctx Context) []string
 	IsReplaying(ctx Context) bool
 	HasLastCompletionResult(ctx Context) bool
 	GetLastCompletionResult(ctx Context, d ...interface{}) error
@@ -167,6 +172,21 @@ func (t *WorkflowInterceptorBase) SetQueryHandler(ctx Context, queryType string,
 	return t.Next.SetQueryHandler(ctx, queryType, handler)
 }
 
+// SetUpdateHandler forwards to t.Next
+func (t *WorkflowInterceptorBase) SetUpdateHandler(ctx Context, updateName string, handler interface{}, options UpdateHandlerOptions) error {
+	return t.Next.SetUpdateHandler(ctx, updateName, handler, options)
+}
+
+// SetSignalHandler forwards to t.Next
+func (t *WorkflowInterceptorBase) SetSignalHandler(ctx Context, signalName string, handler interface{}) error {
+	return t.Next.SetSignalHandler(ctx, signalName, handler)
+}
+
+// GetUnhandledSignalNames forwards to t.Next
+func (t *WorkflowInterceptorBase) GetUnhandledSignalNames(ctx Context) []string {
+	return t.Next.GetUnhandledSignalNames(ctx)
+}
+
 // IsReplaying forwards to t.Next
 func (t *WorkflowInterceptorBase) IsReplaying(ctx Context) bool {
 	return t.Next.IsReplaying(ctx)
@@ -181,3 +201,246 @@ func (t *WorkflowInterceptorBase) HasLastCompletionResult(ctx Context) bool {
 func (t *WorkflowInterceptorBase) GetLastCompletionResult(ctx Context, d ...interface{}) error {
 	return t.Next.GetLastCompletionResult(ctx, d...)
 }
+
+// ActivityInterceptorFactory is used to create a single link in the activity interceptor chain
+type ActivityInterceptorFactory interface {
+	// NewInterceptor creates an interceptor instance. The created instance must delegate every call to
+	// the next parameter for activity code function correctly.
+	NewInterceptor(info *ActivityInfo, next ActivityInterceptor) ActivityInterceptor
+}
+
+// ActivityInterceptor is an interface that can be implemented to intercept calls to the activity function
+// as well calls done by the activity code.
+// Use interceptors.ActivityInterceptorBase as a base struct for implementations that do not want to implement every method.
+// Interceptor implementation must forward calls to the next in the interceptor chain.
+// The chain is instantiated for every activity task, including local activities, and is executed on the
+// goroutine running the activity, so the workflow code restrictions do not apply to it.
+type ActivityInterceptor interface {
+	// Intercepts activity function invocation. The args are the decoded activity arguments and the returned
+	// slice contains the activity function results with the error as the last element.
+	// The context passed to the next interceptor is the one the activity function receives.
+	// activityType argument is for information purposes only and should not be mutated.
+	ExecuteActivity(ctx context.Context, activityType string, args ...interface{}) []interface{}
+
+	GetActivityInfo(ctx context.Context) ActivityInfo
+	GetLogger(ctx context.Context) *zap.Logger
+	GetMetricsScope(ctx context.Context) tally.Scope
+	RecordHeartbeat(ctx context.Context, details ...interface{})
+	HasHeartbeatDetails(ctx context.Context) bool
+	GetHeartbeatDetails(ctx context.Context, d ...interface{}) error
+	GetWorkerStopChannel(ctx context.Context) <-chan struct{}
+}
+
+var _ ActivityInterceptor = (*ActivityInterceptorBase)(nil)
+
+// ActivityInterceptorBase is a helper type that can simplify creation of ActivityInterceptors
+type ActivityInterceptorBase struct {
+	Next ActivityInterceptor
+}
+
+// ExecuteActivity forwards to t.Next
+func (t *ActivityInterceptorBase) ExecuteActivity(ctx context.Context, activityType string, args ...interface{}) []interface{} {
+	return t.Next.ExecuteActivity(ctx, activityType, args...)
+}
+
+// GetActivityInfo forwards to t.Next
+func (t *ActivityInterceptorBase) GetActivityInfo(ctx context.Context) ActivityInfo {
+	return t.Next.GetActivityInfo(ctx)
+}
+
+// GetLogger forwards to t.Next
+func (t *ActivityInterceptorBase) GetLogger(ctx context.Context) *zap.Logger {
+	return t.Next.GetLogger(ctx)
+}
+
+// GetMetricsScope forwards to t.Next
+func (t *ActivityInterceptorBase) GetMetricsScope(ctx context.Context) tally.Scope {
+	return t.Next.GetMetricsScope(ctx)
+}
+
+// RecordHeartbeat forwards to t.Next
+func (t *ActivityInterceptorBase) RecordHeartbeat(ctx context.Context, details ...interface{}) {
+	t.Next.RecordHeartbeat(ctx, details...)
+}
+
+// HasHeartbeatDetails forwards to t.Next
+func (t *ActivityInterceptorBase) HasHeartbeatDetails(ctx context.Context) bool {
+	return t.Next.HasHeartbeatDetails(ctx)
+}
+
+// GetHeartbeatDetails forwards to t.Next
+func (t *ActivityInterceptorBase) GetHeartbeatDetails(ctx context.Context, d ...interface{}) error {
+	return t.Next.GetHeartbeatDetails(ctx, d...)
+}
+
+// GetWorkerStopChannel forwards to t.Next
+func (t *ActivityInterceptorBase) GetWorkerStopChannel(ctx context.Context) <-chan struct{} {
+	return t.Next.GetWorkerStopChannel(ctx)
+}
+
+// ClientInterceptorFactory is used to create a single link in the client interceptor chain
+type ClientInterceptorFactory interface {
+	// NewInterceptor creates an interceptor instance. The created instance must delegate every call to
+	// the next parameter for the client to function correctly.
+	NewInterceptor(next ClientInterceptor) ClientInterceptor
+}
+
+// ClientInterceptor is an interface that can be implemented to intercept calls done through the Client.
+// Use ClientInterceptorBase as a base struct for implementations that do not want to implement every method.
+// Interceptor implementation must forward calls to the next in the interceptor chain. It can inspect and modify
+// options and arguments before forwarding, reject the call by returning an error without forwarding it,
+// and observe the results returned by the next link.
+// Every method is intercepted on its own: when a client method is implemented with other client methods, e.g.
+// ExecuteWorkflow with StartWorkflow or QueryWorkflow with QueryWorkflowWithOptions, those calls go through
+// the whole chain again, so an interceptor overriding both methods observes both calls.
+type ClientInterceptor interface {
+	Client
+}
+
+var _ ClientInterceptor = (*ClientInterceptorBase)(nil)
+
+// ClientInterceptorBase is a helper type that can simplify creation of ClientInterceptors
+type ClientInterceptorBase struct {
+	Next ClientInterceptor
+}
+
+// StartWorkflow forwards to t.Next
+func (t *ClientInterceptorBase) StartWorkflow(ctx context.Context, options StartWorkflowOptions, workflow interface{}, args ...interface{}) (*WorkflowExecution, error) {
+	return t.Next.StartWorkflow(ctx, options, workflow, args...)
+}
+
+// ExecuteWorkflow forwards to t.Next
+func (t *ClientInterceptorBase) ExecuteWorkflow(ctx context.Context, options StartWorkflowOptions, workflow interface{}, args ...interface{}) (WorkflowRun, error) {
+	return t.Next.ExecuteWorkflow(ctx, options, workflow, args...)
+}
+
+// GetWorkflow forwards to t.Next
+func (t *ClientInterceptorBase) GetWorkflow(ctx context.Context, workflowID string, runID string) WorkflowRun {
+	return t.Next.GetWorkflow(ctx, workflowID, runID)
+}
+
+// SignalWorkflow forwards to t.Next
+func (t *ClientInterceptorBase) SignalWorkflow(ctx context.Context, workflowID string, runID string, signalName string, arg interface{}) error {
+	return t.Next.SignalWorkflow(ctx, workflowID, runID, signalName, arg)
+}
+
+// SignalWithStartWorkflow forwards to t.Next
+func (t *ClientInterceptorBase) SignalWithStartWorkflow(ctx context.Context, workflowID string, signalName string, signalArg interface{},
+	options StartWorkflowOptions, workflow interface{}, workflowArgs ...interface{}) (*WorkflowExecution, error) {
+	return t.Next.SignalWithStartWorkflow(ctx, workflowID, signalName, signalArg, options, workflow, workflowArgs...)
+}
+
+// CancelWorkflow forwards to t.Next
+func (t *ClientInterceptorBase) CancelWorkflow(ctx context.Context, workflowID string, runID string) error {
+	return t.Next.CancelWorkflow(ctx, workflowID, runID)
+}
+
+// TerminateWorkflow forwards to t.Next
+func (t *ClientInterceptorBase) TerminateWorkflow(ctx context.Context, workflowID string, runID string, reason string, details []byte) error {
+	return t.Next.TerminateWorkflow(ctx, workflowID, runID, reason, details)
+}
+
+// ResetWorkflow forwards to t.Next
+func (t *ClientInterceptorBase) ResetWorkflow(ctx context.Context, request *ResetWorkflowRequest) (*WorkflowExecution, error) {
+	return t.Next.ResetWorkflow(ctx, request)
+}
+
+// ResetWorkflows forwards to t.Next
+func (t *ClientInterceptorBase) ResetWorkflows(ctx context.Context, request *ResetWorkflowsRequest) (*BatchWorkflowResponse, error) {
+	return t.Next.ResetWorkflows(ctx, request)
+}
+
+// BatchWorkflow forwards to t.Next
+func (t *ClientInterceptorBase) BatchWorkflow(ctx context.Context, request *BatchWorkflowRequest) (*BatchWorkflowResponse, error) {
+	return t.Next.BatchWorkflow(ctx, request)
+}
+
+// GetWorkflowHistory forwards to t.Next
+func (t *ClientInterceptorBase) GetWorkflowHistory(ctx context.Context, workflowID string, runID string, isLongPoll bool, filterType s.HistoryEventFilterType) HistoryEventIterator {
+	return t.Next.GetWorkflowHistory(ctx, workflowID, runID, isLongPoll, filterType)
+}
+
+// CompleteActivity forwards to t.Next
+func (t *ClientInterceptorBase) CompleteActivity(ctx context.Context, taskToken []byte, result interface{}, err error) error {
+	return t.Next.CompleteActivity(ctx, taskToken, result, err)
+}
+
+// CompleteActivityByID forwards to t.Next
+func (t *ClientInterceptorBase) CompleteActivityByID(ctx context.Context, domain, workflowID, runID, activityID string, result interface{}, err error) error {
+	return t.Next.CompleteActivityByID(ctx, domain, workflowID, runID, activityID, result, err)
+}
+
+// RecordActivityHeartbeat forwards to t.Next
+func (t *ClientInterceptorBase) RecordActivityHeartbeat(ctx context.Context, taskToken []byte, details ...interface{}) error {
+	return t.Next.RecordActivityHeartbeat(ctx, taskToken, details...)
+}
+
+// RecordActivityHeartbeatByID forwards to t.Next
+func (t *ClientInterceptorBase) RecordActivityHeartbeatByID(ctx context.Context, domain, workflowID, runID, activityID string, details ...interface{}) error {
+	return t.Next.RecordActivityHeartbeatByID(ctx, domain, workflowID, runID, activityID, details...)
+}
+
+// ListClosedWorkflow forwards to t.Next
+func (t *ClientInterceptorBase) ListClosedWorkflow(ctx context.Context, request *s.ListClosedWorkflowExecutionsRequest) (*s.ListClosedWorkflowExecutionsResponse, error) {
+	return t.Next.ListClosedWorkflow(ctx, request)
+}
+
+// ListOpenWorkflow forwards to t.Next
+func (t *ClientInterceptorBase) ListOpenWorkflow(ctx context.Context, request *s.ListOpenWorkflowExecutionsRequest) (*s.ListOpenWorkflowExecutionsResponse, error) {
+	return t.Next.ListOpenWorkflow(ctx, request)
+}
+
+// ListWorkflow forwards to t.Next
+func (t *ClientInterceptorBase) ListWorkflow(ctx context.Context, request *s.ListWorkflowExecutionsRequest) (*s.ListWorkflowExecutionsResponse, error) {
+	return t.Next.ListWorkflow(ctx, request)
+}
+
+// ListArchivedWorkflow forwards to t.Next
+func (t *ClientInterceptorBase) ListArchivedWorkflow(ctx context.Context, request *s.ListArchivedWorkflowExecutionsRequest) (*s.ListArchivedWorkflowExecutionsResponse, error) {
+	return t.Next.ListArchivedWorkflow(ctx, request)
+}
+
+// ScanWorkflow forwards to t.Next
+func (t *ClientInterceptorBase) ScanWorkflow(ctx context.Context, request *s.ListWorkflowExecutionsRequest) (*s.ListWorkflowExecutionsResponse, error) {
+	return t.Next.ScanWorkflow(ctx, request)
+}
+
+// CountWorkflow forwards to t.Next
+func (t *ClientInterceptorBase) CountWorkflow(ctx context.Context, request *s.CountWorkflowExecutionsRequest) (*s.CountWorkflowExecutionsResponse, error) {
+	return t.Next.CountWorkflow(ctx, request)
+}
+
+// GetSearchAttributes forwards to t.Next
+func (t *ClientInterceptorBase) GetSearchAttributes(ctx context.Context) (*s.GetSearchAttributesResponse, error) {
+	return t.Next.GetSearchAttributes(ctx)
+}
+
+// QueryWorkflow forwards to t.Next
+func (t *ClientInterceptorBase) QueryWorkflow(ctx context.Context, workflowID string, runID string, queryType string, args ...interface{}) (Value, error) {
+	return t.Next.QueryWorkflow(ctx, workflowID, runID, queryType, args...)
+}
+
+// QueryWorkflowWithOptions forwards to t.Next
+func (t *ClientInterceptorBase) QueryWorkflowWithOptions(ctx context.Context, request *QueryWorkflowWithOptionsRequest) (*QueryWorkflowWithOptionsResponse, error) {
+	return t.Next.QueryWorkflowWithOptions(ctx, request)
+}
+
+// UpdateWorkflow forwards to t.Next
+func (t *ClientInterceptorBase) UpdateWorkflow(ctx context.Context, workflowID string, runID string, updateName string, args ...interface{}) (Value, error) {
+	return t.Next.UpdateWorkflow(ctx, workflowID, runID, updateName, args...)
+}
+
+// UpdateWorkflowWithOptions forwards to t.Next
+func (t *ClientInterceptorBase) UpdateWorkflowWithOptions(ctx context.Context, request *UpdateWorkflowWithOptionsRequest) (Value, error) {
+	return t.Next.UpdateWorkflowWithOptions(ctx, request)
+}
+
+// DescribeWorkflowExecution forwards to t.Next
+func (t *ClientInterceptorBase) DescribeWorkflowExecution(ctx context.Context, workflowID, runID string) (*s.DescribeWorkflowExecutionResponse, error) {
+	return t.Next.DescribeWorkflowExecution(ctx, workflowID, runID)
+}
+
+// DescribeTaskList forwards to t.Next
+func (t *ClientInterceptorBase) DescribeTaskList(ctx context.Context, tasklist string, tasklistType s.TaskListType) (*s.DescribeTaskListResponse, error) {
+	return t.Next.DescribeTaskList(ctx, tasklist, tasklistType)
+}
@@ -28,6 +28,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
@@ -45,6 +46,11 @@ type (
 		GetFunction() interface{}
 	}
 
+	// autoHeartbeatActivity is implemented by activities that can be registered with EnableAutoHeartbeat.
+	autoHeartbeatActivity interface {
+		isAutoHeartbeatEnabled() bool
+	}
+
 	activityInfo struct {
 		activityID string
 	}
@@ -61,7 +67,7 @@ type (
 		ScheduleToStartTimeoutSeconds int32
 		StartToCloseTimeoutSeconds    int32
 		HeartbeatTimeoutSeconds       int32
-		WaitForCancellation           bool
+		CancellationType              CancellationType
 		OriginalTaskListName          string
 		RetryPolicy                   *shared.RetryPolicy
 	}
@@ -133,6 +139,17 @@ type (
 		workerStopChannel  <-chan struct{}
 		contextPropagators []ContextPropagator
 		tracer             opentracing.Tracer
+		interceptors       []ActivityInterceptorFactory
+
+		// heartbeatLock guards lastHeartbeatDetails, the details of the last heartbeat repeated by the auto heartbeat
+		heartbeatLock        sync.Mutex
+		lastHeartbeatDetails []byte
+	}
+
+	// activityEnvironmentInterceptor is the last link of the activity interceptor chain.
+	// It invokes the activity function and serves the calls that read the activity environment.
+	activityEnvironmentInterceptor struct {
+		fn interface{}
 	}
 
 	// context.WithValue need this type instead of basic type string to avoid lint error
@@ -143,8 +160,14 @@ const (
 	activityEnvContextKey          contextKey = "activityEnv"
 	activityOptionsContextKey      contextKey = "activityOptions"
 	localActivityOptionsContextKey contextKey = "localActivityOptions"
+	activityInterceptorContextKey  contextKey = "activityInterceptor"
+	autoHeartbeatContextKey        contextKey = "autoHeartbeat"
 )
 
+// autoHeartbeatRatio is the fraction of the heartbeat timeout between heartbeats recorded by the worker
+// for activities registered with EnableAutoHeartbeat.
+const autoHeartbeatRatio = 0.5
+
 func getActivityEnv(ctx context.Context) *activityEnvironment {
 	env := ctx.Value(activityEnvContextKey)
 	if env == nil {
@@ -153,6 +176,75 @@ func getActivityEnv(ctx context.Context) *activityEnvironment {
 	return env.(*activityEnvironment)
 }
 
+func (env *activityEnvironment) getLastHeartbeatDetails() []byte {
+	env.heartbeatLock.Lock()
+	defer env.heartbeatLock.Unlock()
+	return env.lastHeartbeatDetails
+}
+
+func (env *activityEnvironment) setLastHeartbeatDetails(details []byte) {
+	env.heartbeatLock.Lock()
+	defer env.heartbeatLock.Unlock()
+	env.lastHeartbeatDetails = details
+}
+
+func getActivityInterceptor(ctx context.Context) ActivityInterceptor {
+	if i, ok := ctx.Value(activityInterceptorContextKey).(ActivityInterceptor); ok {
+		return i
+	}
+	return &activityEnvironmentInterceptor{}
+}
+
+// newActivityInterceptors instantiates the interceptor chain configured for the activity environment and
+// stores its head in the returned context. Contexts without activity environment get no interceptors.
+func newActivityInterceptors(ctx context.Context, fn interface{}) (context.Context, ActivityInterceptor) {
+	var interceptor ActivityInterceptor = &activityEnvironmentInterceptor{fn: fn}
+	if ctx == nil || ctx.Value(activityEnvContextKey) == nil {
+		return ctx, interceptor
+	}
+	env := getActivityEnv(ctx)
+	if len(env.interceptors) > 0 {
+		info := interceptor.GetActivityInfo(ctx)
+		for i := len(env.interceptors) - 1; i >= 0; i-- {
+			interceptor = env.interceptors[i].NewInterceptor(&info, interceptor)
+		}
+	}
+	return context.WithValue(ctx, activityInterceptorContextKey, interceptor), interceptor
+}
+
+// startAutoHeartbeat records heartbeats through the interceptor chain at a fraction of the heartbeat timeout until
+// the returned function is called. The heartbeats carry no details, the environment repeats the last ones.
+func startAutoHeartbeat(ctx context.Context, interceptor ActivityInterceptor) (stop func()) {
+	env, _ := ctx.Value(activityEnvContextKey).(*activityEnvironment)
+	if env == nil || env.isLocalActivity || env.heartbeatTimeout <= 0 {
+		return func() {}
+	}
+	stopCh := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(time.Duration(autoHeartbeatRatio * float64(env.heartbeatTimeout)))
+		defer ticker.Stop()
+		ctx := context.WithValue(ctx, autoHeartbeatContextKey, true)
+		for {
+			select {
+			case <-ticker.C:
+				interceptor.RecordHeartbeat(ctx)
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+	return func() { close(stopCh) }
+}
+
+func (a *activityEnvironmentInterceptor) ExecuteActivity(ctx context.Context, activityType string, args ...interface{}) (results []interface{}) {
+	ae := &activityExecutor{name: activityType, fn: a.fn}
+	retValues := ae.executeWithActualArgsWithoutParseResult(ctx, args)
+	for _, r := range retValues {
+		results = append(results, r.Interface())
+	}
+	return
+}
+
 func getActivityOptions(ctx Context) *activityOptions {
 	eap := ctx.Value(activityOptionsContextKey)
 	if eap == nil {
@@ -0,0 +1,311 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	s "go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/internal/common"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultBatchConcurrency = 10
+	defaultBatchRPS         = 50
+	defaultBatchPageSize    = 100
+)
+
+type (
+	// BatchOperation is the operation BatchWorkflow applies to every matching workflow execution.
+	// Use BatchCancel, BatchTerminate, BatchSignal or BatchReset to create one.
+	BatchOperation interface {
+		apply(ctx context.Context, c Client, execution WorkflowExecution) error
+	}
+
+	// BatchWorkflowRequest is the request to BatchWorkflow
+	BatchWorkflowRequest struct {
+		// Query is a required visibility query selecting the executions to operate on,
+		// e.g. "WorkflowType = 'OrderWorkflow' AND CloseTime = missing".
+		Query string
+
+		// Operation is a required field specifying what to do with every matching execution.
+		Operation BatchOperation
+
+		// Concurrency is an optional field limiting how many operations run at the same time.
+		// Default: 10
+		Concurrency int
+
+		// RPS is an optional field limiting how many operations are started per second.
+		// Default: 50
+		RPS float64
+
+		// PageSize is an optional field specifying how many executions are listed per visibility query page.
+		// Default: 100
+		PageSize int32
+
+		// Checkpoint is an optional field used to resume a batch from a checkpoint returned by a previous
+		// BatchWorkflow call or reported through ProgressHandler. The Query must be the same as the one of the
+		// interrupted batch.
+		Checkpoint []byte
+
+		// ProgressHandler is an optional callback invoked after every page of executions has been processed.
+		ProgressHandler func(progress BatchWorkflowProgress)
+	}
+
+	// BatchWorkflowProgress reports the progress of BatchWorkflow.
+	BatchWorkflowProgress struct {
+		// Succeeded is the number of executions operated on successfully, including those before the checkpoint
+		// the batch was resumed from.
+		Succeeded int
+
+		// Failed is the number of executions the operation failed for, including those before the checkpoint
+		// the batch was resumed from.
+		Failed int
+
+		// Failures are the executions of the last processed page the operation failed for.
+		Failures []BatchWorkflowFailure
+
+		// Checkpoint can be passed to BatchWorkflowRequest.Checkpoint to resume the batch after the last
+		// processed page.
+		Checkpoint []byte
+	}
+
+	// BatchWorkflowResponse is the response to BatchWorkflow
+	BatchWorkflowResponse struct {
+		// Succeeded is the total number of executions operated on successfully.
+		Succeeded int
+
+		// Failed is the total number of executions the operation failed for.
+		Failed int
+
+		// Failures are the executions the operation failed for during this call.
+		Failures []BatchWorkflowFailure
+
+		// Checkpoint can be passed to BatchWorkflowRequest.Checkpoint to resume the batch if BatchWorkflow
+		// returned an error.
+		Checkpoint []byte
+	}
+
+	// BatchWorkflowFailure describes an execution a batch operation failed for.
+	BatchWorkflowFailure struct {
+		Execution WorkflowExecution
+		Err       error
+	}
+
+	// batchCheckpoint is the content of the opaque checkpoint token
+	batchCheckpoint struct {
+		Query         string `json:"query"`
+		NextPageToken []byte `json:"nextPageToken,omitempty"`
+		Succeeded     int    `json:"succeeded"`
+		Failed        int    `json:"failed"`
+		Done          bool   `json:"done,omitempty"`
+	}
+
+	batchCancel struct{}
+
+	batchTerminate struct {
+		reason  string
+		details []byte
+	}
+
+	batchSignal struct {
+		signalName string
+		arg        interface{}
+	}
+
+	batchReset struct {
+		reason     string
+		resetPoint ResetPoint
+	}
+)
+
+// BatchCancel requests cancellation of every matching workflow execution.
+func BatchCancel() BatchOperation {
+	return batchCancel{}
+}
+
+// BatchTerminate terminates every matching workflow execution.
+func BatchTerminate(reason string, details []byte) BatchOperation {
+	return batchTerminate{reason: reason, details: details}
+}
+
+// BatchSignal sends the signal to every matching workflow execution.
+func BatchSignal(signalName string, arg interface{}) BatchOperation {
+	return batchSignal{signalName: signalName, arg: arg}
+}
+
+// BatchReset resets every matching workflow execution to the given reset point.
+func BatchReset(reason string, resetPoint ResetPoint) BatchOperation {
+	return batchReset{reason: reason, resetPoint: resetPoint}
+}
+
+func (o batchCancel) apply(ctx context.Context, c Client, execution WorkflowExecution) error {
+	return c.CancelWorkflow(ctx, execution.ID, execution.RunID)
+}
+
+func (o batchTerminate) apply(ctx context.Context, c Client, execution WorkflowExecution) error {
+	return c.TerminateWorkflow(ctx, execution.ID, execution.RunID, o.reason, o.details)
+}
+
+func (o batchSignal) apply(ctx context.Context, c Client, execution WorkflowExecution) error {
+	return c.SignalWorkflow(ctx, execution.ID, execution.RunID, o.signalName, o.arg)
+}
+
+func (o batchReset) apply(ctx context.Context, c Client, execution WorkflowExecution) error {
+	_, err := c.ResetWorkflow(ctx, &ResetWorkflowRequest{
+		WorkflowID: execution.ID,
+		RunID:      execution.RunID,
+		Reason:     o.reason,
+		ResetPoint: o.resetPoint,
+	})
+	return err
+}
+
+// BatchWorkflow applies request.Operation to every workflow execution matching the visibility query request.Query.
+// Executions are listed page by page; the operations of a page run concurrently, bounded by request.Concurrency
+// and request.RPS, and request.ProgressHandler is invoked once the page is done. A failed operation is reported
+// and does not stop the batch. If listing fails or ctx is done, the response is returned along with the error and
+// its Checkpoint resumes the batch from the interrupted page, so operations of that page may be applied twice.
+func (wc *workflowClient) BatchWorkflow(ctx context.Context, request *BatchWorkflowRequest) (*BatchWorkflowResponse, error) {
+	if request.Query == "" {
+		return nil, errors.New("query is required")
+	}
+	if request.Operation == nil {
+		return nil, errors.New("operation is required")
+	}
+	checkpoint := batchCheckpoint{Query: request.Query}
+	if len(request.Checkpoint) > 0 {
+		if err := json.Unmarshal(request.Checkpoint, &checkpoint); err != nil {
+			return nil, fmt.Errorf("invalid checkpoint: %v", err)
+		}
+		if checkpoint.Query != request.Query {
+			return nil, fmt.Errorf("checkpoint was created for query %q", checkpoint.Query)
+		}
+	}
+	concurrency := request.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	rps := request.RPS
+	if rps <= 0 {
+		rps = defaultBatchRPS
+	}
+	pageSize := request.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultBatchPageSize
+	}
+	limiter := rate.NewLimiter(rate.Limit(rps), 1)
+
+	response := &BatchWorkflowResponse{}
+	interrupted := func(err error) (*BatchWorkflowResponse, error) {
+		response.Succeeded = checkpoint.Succeeded
+		response.Failed = checkpoint.Failed
+		response.Checkpoint, _ = json.Marshal(checkpoint)
+		return response, err
+	}
+	for !checkpoint.Done {
+		page, err := wc.intercepted().ListWorkflow(ctx, &s.ListWorkflowExecutionsRequest{
+			Domain:        common.StringPtr(wc.domain),
+			PageSize:      common.Int32Ptr(pageSize),
+			NextPageToken: checkpoint.NextPageToken,
+			Query:         common.StringPtr(request.Query),
+		})
+		if err != nil {
+			return interrupted(err)
+		}
+
+		failures, err := wc.applyBatchOperation(ctx, request.Operation, page.Executions, limiter, concurrency)
+		if err != nil {
+			return interrupted(err)
+		}
+		response.Failures = append(response.Failures, failures...)
+		checkpoint.Succeeded += len(page.Executions) - len(failures)
+		checkpoint.Failed += len(failures)
+		checkpoint.NextPageToken = page.NextPageToken
+		checkpoint.Done = len(page.NextPageToken) == 0
+
+		if request.ProgressHandler != nil {
+			token, _ := json.Marshal(checkpoint)
+			request.ProgressHandler(BatchWorkflowProgress{
+				Succeeded:  checkpoint.Succeeded,
+				Failed:     checkpoint.Failed,
+				Failures:   failures,
+				Checkpoint: token,
+			})
+		}
+	}
+	response.Succeeded = checkpoint.Succeeded
+	response.Failed = checkpoint.Failed
+	response.Checkpoint, _ = json.Marshal(checkpoint)
+	return response, nil
+}
+
+// applyBatchOperation applies the operation to a page of executions and returns those it failed for. It only
+// returns an error if ctx is done before every operation of the page was started.
+func (wc *workflowClient) applyBatchOperation(
+	ctx context.Context,
+	operation BatchOperation,
+	executions []*s.WorkflowExecutionInfo,
+	limiter *rate.Limiter,
+	concurrency int,
+) ([]BatchWorkflowFailure, error) {
+	var (
+		wg       sync.WaitGroup
+		lock     sync.Mutex
+		failures []BatchWorkflowFailure
+		err      error
+	)
+	// the operations go through the interceptor chain like direct calls of the client methods
+	c := wc.intercepted()
+	slots := make(chan struct{}, concurrency)
+	for _, info := range executions {
+		if err = limiter.Wait(ctx); err != nil {
+			break
+		}
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		if err != nil {
+			break
+		}
+		execution := WorkflowExecution{ID: info.Execution.GetWorkflowId(), RunID: info.Execution.GetRunId()}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			if err := operation.apply(ctx, c, execution); err != nil {
+				lock.Lock()
+				failures = append(failures, BatchWorkflowFailure{Execution: execution, Err: err})
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return failures, err
+}
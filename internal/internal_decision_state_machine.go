@@ -23,6 +23,8 @@ package internal
 import (
 	"container/list"
 	"fmt"
+	"time"
+
 	s "go.uber.org/cadence/.gen/go/shared"
 	"go.uber.org/cadence/internal/common"
 	"go.uber.org/cadence/internal/common/util"
@@ -68,7 +70,8 @@ type (
 
 	activityDecisionStateMachine struct {
 		*decisionStateMachineBase
-		attributes *s.ScheduleActivityTaskDecisionAttributes
+		attributes       *s.ScheduleActivityTaskDecisionAttributes
+		cancellationType CancellationType
 	}
 
 	timerDecisionStateMachine struct {
@@ -79,7 +82,8 @@ type (
 
 	childWorkflowDecisionStateMachine struct {
 		*decisionStateMachineBase
-		attributes *s.StartChildWorkflowExecutionDecisionAttributes
+		attributes       *s.StartChildWorkflowExecutionDecisionAttributes
+		cancellationType CancellationType
 	}
 
 	naiveDecisionStateMachine struct {
@@ -225,11 +229,12 @@ func (h *decisionsHelper) newDecisionStateMachineBase(decisionType decisionType,
 	}
 }
 
-func (h *decisionsHelper) newActivityDecisionStateMachine(attributes *s.ScheduleActivityTaskDecisionAttributes) *activityDecisionStateMachine {
+func (h *decisionsHelper) newActivityDecisionStateMachine(attributes *s.ScheduleActivityTaskDecisionAttributes, cancellationType CancellationType) *activityDecisionStateMachine {
 	base := h.newDecisionStateMachineBase(decisionTypeActivity, attributes.GetActivityId())
 	return &activityDecisionStateMachine{
 		decisionStateMachineBase: base,
 		attributes:               attributes,
+		cancellationType:         cancellationType,
 	}
 }
 
@@ -241,11 +246,12 @@ func (h *decisionsHelper) newTimerDecisionStateMachine(attributes *s.StartTimerD
 	}
 }
 
-func (h *decisionsHelper) newChildWorkflowDecisionStateMachine(attributes *s.StartChildWorkflowExecutionDecisionAttributes) *childWorkflowDecisionStateMachine {
+func (h *decisionsHelper) newChildWorkflowDecisionStateMachine(attributes *s.StartChildWorkflowExecutionDecisionAttributes, cancellationType CancellationType) *childWorkflowDecisionStateMachine {
 	base := h.newDecisionStateMachineBase(decisionTypeChildWorkflow, attributes.GetWorkflowId())
 	return &childWorkflowDecisionStateMachine{
 		decisionStateMachineBase: base,
 		attributes:               attributes,
+		cancellationType:         cancellationType,
 	}
 }
 
@@ -442,6 +448,15 @@ func (d *activityDecisionStateMachine) getDecision() *s.Decision {
 	}
 }
 
+func (d *activityDecisionStateMachine) cancel() {
+	if d.cancellationType == CancellationTypeAbandon && d.state != decisionStateCreated {
+		// abandoned activity is left running, no cancellation is requested from the server
+		d.history = append(d.history, eventCancel)
+		return
+	}
+	d.decisionStateMachineBase.cancel()
+}
+
 func (d *activityDecisionStateMachine) handleDecisionSent() {
 	switch d.state {
 	case decisionStateCanceledAfterInitiated:
@@ -553,6 +568,11 @@ func (d *childWorkflowDecisionStateMachine) handleCancelFailedEvent() {
 }
 
 func (d *childWorkflowDecisionStateMachine) cancel() {
+	if d.cancellationType == CancellationTypeAbandon && d.state != decisionStateCreated {
+		// abandoned child workflow is left running, no cancellation is requested from the server
+		d.history = append(d.history, eventCancel)
+		return
+	}
 	switch d.state {
 	case decisionStateStarted:
 		d.moveState(decisionStateCanceledAfterStarted, eventCancel)
@@ -709,8 +729,8 @@ func (h *decisionsHelper) addDecision(decision decisionStateMachine) {
 	h.decisions[decision.getID()] = element
 }
 
-func (h *decisionsHelper) scheduleActivityTask(attributes *s.ScheduleActivityTaskDecisionAttributes) decisionStateMachine {
-	decision := h.newActivityDecisionStateMachine(attributes)
+func (h *decisionsHelper) scheduleActivityTask(attributes *s.ScheduleActivityTaskDecisionAttributes, cancellationType CancellationType) decisionStateMachine {
+	decision := h.newActivityDecisionStateMachine(attributes, cancellationType)
 	h.addDecision(decision)
 	return decision
 }
@@ -822,8 +842,8 @@ func (h *decisionsHelper) recordMutableSideEffectMarker(mutableSideEffectID stri
 	return decision
 }
 
-func (h *decisionsHelper) startChildWorkflowExecution(attributes *s.StartChildWorkflowExecutionDecisionAttributes) decisionStateMachine {
-	decision := h.newChildWorkflowDecisionStateMachine(attributes)
+func (h *decisionsHelper) startChildWorkflowExecution(attributes *s.StartChildWorkflowExecutionDecisionAttributes, cancellationType CancellationType) decisionStateMachine {
+	decision := h.newChildWorkflowDecisionStateMachine(attributes, cancellationType)
 	h.addDecision(decision)
 	return decision
 }
@@ -1055,6 +1075,58 @@ func (h *decisionsHelper) getDecisions(markAsSent bool) []*s.Decision {
 	return result
 }
 
+// pendingOperations returns the activities, timers, child workflows and requests to external workflows that were
+// scheduled but are not completed yet, in the order they were scheduled.
+func (h *decisionsHelper) pendingOperations() PendingOperations {
+	var result PendingOperations
+	for curr := h.orderedDecisions.Front(); curr != nil; curr = curr.Next() {
+		d := curr.Value.(decisionStateMachine)
+		if d.isDone() {
+			continue
+		}
+		state := d.getState().String()
+		switch d := d.(type) {
+		case *activityDecisionStateMachine:
+			result.Activities = append(result.Activities, PendingActivityInfo{
+				ActivityID:   d.attributes.GetActivityId(),
+				ActivityType: d.attributes.ActivityType.GetName(),
+				State:        state,
+			})
+		case *timerDecisionStateMachine:
+			result.Timers = append(result.Timers, PendingTimerInfo{
+				TimerID:            d.attributes.GetTimerId(),
+				StartToFireTimeout: time.Duration(d.attributes.GetStartToFireTimeoutSeconds()) * time.Second,
+				State:              state,
+			})
+		case *childWorkflowDecisionStateMachine:
+			result.ChildWorkflows = append(result.ChildWorkflows, PendingChildWorkflowInfo{
+				Domain:       d.attributes.GetDomain(),
+				WorkflowID:   d.attributes.GetWorkflowId(),
+				WorkflowType: d.attributes.WorkflowType.GetName(),
+				State:        state,
+			})
+		case *signalExternalWorkflowDecisionStateMachine:
+			attributes := d.decision.SignalExternalWorkflowExecutionDecisionAttributes
+			result.ExternalSignals = append(result.ExternalSignals, PendingExternalWorkflowInfo{
+				Domain:     attributes.GetDomain(),
+				WorkflowID: attributes.Execution.GetWorkflowId(),
+				RunID:      attributes.Execution.GetRunId(),
+				SignalName: attributes.GetSignalName(),
+				State:      state,
+			})
+		case *cancelExternalWorkflowDecisionStateMachine:
+			attributes := d.decision.RequestCancelExternalWorkflowExecutionDecisionAttributes
+			result.ExternalCancels = append(result.ExternalCancels, PendingExternalWorkflowInfo{
+				Domain:     attributes.GetDomain(),
+				WorkflowID: attributes.GetWorkflowId(),
+				RunID:      attributes.GetRunId(),
+				State:      state,
+			})
+		}
+	}
+	return result
+}
+
 func (h *decisionsHelper) isCancelExternalWorkflowEventForChildWorkflow(cancellationID string) bool {
 	// the cancellationID, i.e. Control in RequestCancelExternalWorkflowExecutionInitiatedEventAttributes
 	// will be empty if the event is for child workflow.
@@ -22,6 +22,7 @@ package internal
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	s "go.uber.org/cadence/.gen/go/shared"
@@ -170,7 +171,7 @@ func Test_ActivityStateMachine_CompleteWithoutCancel(t *testing.T) {
 	h := newDecisionsHelper()
 
 	// schedule activity
-	d := h.scheduleActivityTask(attributes)
+	d := h.scheduleActivityTask(attributes, CancellationTypeTryCancel)
 	require.Equal(t, decisionStateCreated, d.getState())
 	decisions := h.getDecisions(true)
 	require.Equal(t, decisionStateDecisionSent, d.getState())
@@ -195,7 +196,7 @@ func Test_ActivityStateMachine_CancelBeforeSent(t *testing.T) {
 	h := newDecisionsHelper()
 
 	// schedule activity
-	d := h.scheduleActivityTask(attributes)
+	d := h.scheduleActivityTask(attributes, CancellationTypeTryCancel)
 	require.Equal(t, decisionStateCreated, d.getState())
 
 	// cancel before decision sent, this will put decision state machine directly into completed state
@@ -216,7 +217,7 @@ func Test_ActivityStateMachine_CancelAfterSent(t *testing.T) {
 	h := newDecisionsHelper()
 
 	// schedule activity
-	d := h.scheduleActivityTask(attributes)
+	d := h.scheduleActivityTask(attributes, CancellationTypeTryCancel)
 	require.Equal(t, decisionStateCreated, d.getState())
 	decisions := h.getDecisions(true)
 	require.Equal(t, 1, len(decisions))
@@ -240,6 +241,34 @@ func Test_ActivityStateMachine_CancelAfterSent(t *testing.T) {
 	require.Equal(t, 0, len(h.getDecisions(false)))
 }
 
+func Test_ActivityStateMachine_AbandonAfterInitiated(t *testing.T) {
+	t.Parallel()
+	activityID := "test-activity-1"
+	attributes := &s.ScheduleActivityTaskDecisionAttributes{
+		ActivityId: common.StringPtr(activityID),
+	}
+	h := newDecisionsHelper()
+
+	// schedule activity
+	d := h.scheduleActivityTask(attributes, CancellationTypeAbandon)
+	decisions := h.getDecisions(true)
+	require.Equal(t, 1, len(decisions))
+	require.Equal(t, s.DecisionTypeScheduleActivityTask, decisions[0].GetDecisionType())
+
+	// activity scheduled
+	h.handleActivityTaskScheduled(1, activityID)
+	require.Equal(t, decisionStateInitiated, d.getState())
+
+	// cancel activity, no cancellation is requested from the server
+	h.requestCancelActivityTask(activityID)
+	require.Equal(t, decisionStateInitiated, d.getState())
+	require.Equal(t, 0, len(h.getDecisions(true)))
+
+	// activity completed
+	h.handleActivityTaskClosed(activityID)
+	require.Equal(t, decisionStateCompleted, d.getState())
+}
+
 func Test_ActivityStateMachine_CompletedAfterCancel(t *testing.T) {
 	t.Parallel()
 	activityID := "test-activity-1"
@@ -249,7 +278,7 @@ func Test_ActivityStateMachine_CompletedAfterCancel(t *testing.T) {
 	h := newDecisionsHelper()
 
 	// schedule activity
-	d := h.scheduleActivityTask(attributes)
+	d := h.scheduleActivityTask(attributes, CancellationTypeTryCancel)
 	require.Equal(t, decisionStateCreated, d.getState())
 	decisions := h.getDecisions(true)
 	require.Equal(t, 1, len(decisions))
@@ -282,7 +311,7 @@ func Test_ActivityStateMachine_PanicInvalidStateTransition(t *testing.T) {
 	h := newDecisionsHelper()
 
 	// schedule activity
-	h.scheduleActivityTask(attributes)
+	h.scheduleActivityTask(attributes, CancellationTypeTryCancel)
 
 	// verify that using invalid activity id will panic
 	err := runAndCatchPanic(func() {
@@ -311,7 +340,7 @@ func Test_ChildWorkflowStateMachine_Basic(t *testing.T) {
 	h := newDecisionsHelper()
 
 	// start child workflow
-	d := h.startChildWorkflowExecution(attributes)
+	d := h.startChildWorkflowExecution(attributes, CancellationTypeWaitCancellationCompleted)
 	require.Equal(t, decisionStateCreated, d.getState())
 
 	// send decision
@@ -350,7 +379,7 @@ func Test_ChildWorkflowStateMachine_CancelSucceed(t *testing.T) {
 	h := newDecisionsHelper()
 
 	// start child workflow
-	d := h.startChildWorkflowExecution(attributes)
+	d := h.startChildWorkflowExecution(attributes, CancellationTypeWaitCancellationCompleted)
 	// send decision
 	decisions := h.getDecisions(true)
 	// child workflow initiated
@@ -395,7 +424,7 @@ func Test_ChildWorkflowStateMachine_InvalidStates(t *testing.T) {
 	h := newDecisionsHelper()
 
 	// start child workflow
-	d := h.startChildWorkflowExecution(attributes)
+	d := h.startChildWorkflowExecution(attributes, CancellationTypeWaitCancellationCompleted)
 	require.Equal(t, decisionStateCreated, d.getState())
 
 	// invalid: start child workflow failed before decision was sent
@@ -478,7 +507,7 @@ func Test_ChildWorkflowStateMachine_CancelFailed(t *testing.T) {
 	h := newDecisionsHelper()
 
 	// start child workflow
-	d := h.startChildWorkflowExecution(attributes)
+	d := h.startChildWorkflowExecution(attributes, CancellationTypeWaitCancellationCompleted)
 	// send decision
 	h.getDecisions(true)
 	// child workflow initiated
@@ -637,3 +666,53 @@ func runAndCatchPanic(f func()) (err *PanicError) {
 	f()
 	return nil
 }
+
+func Test_PendingOperations(t *testing.T) {
+	t.Parallel()
+	h := newDecisionsHelper()
+
+	h.scheduleActivityTask(&s.ScheduleActivityTaskDecisionAttributes{
+		ActivityId:   common.StringPtr("activity-1"),
+		ActivityType: &s.ActivityType{Name: common.StringPtr("activity-type")},
+	}, CancellationTypeTryCancel)
+	h.scheduleActivityTask(&s.ScheduleActivityTaskDecisionAttributes{
+		ActivityId: common.StringPtr("activity-2"),
+	}, CancellationTypeTryCancel)
+	h.startTimer(&s.StartTimerDecisionAttributes{
+		TimerId:                   common.StringPtr("timer-1"),
+		StartToFireTimeoutSeconds: common.Int64Ptr(60),
+	})
+	h.startChildWorkflowExecution(&s.StartChildWorkflowExecutionDecisionAttributes{
+		Domain:       common.StringPtr("child-domain"),
+		WorkflowId:   common.StringPtr("child-1"),
+		WorkflowType: &s.WorkflowType{Name: common.StringPtr("child-type")},
+	}, CancellationTypeWaitCancellationCompleted)
+	h.signalExternalWorkflowExecution("external-domain", "external-1", "run-1", "signal-1", nil, "signal-id", false)
+	h.requestCancelExternalWorkflowExecution("external-domain", "external-2", "run-2", "cancel-id", false)
+	h.recordSideEffectMarker(1, nil)
+	h.getDecisions(true)
+
+	// activity-1 started, activity-2 completed
+	h.handleActivityTaskScheduled(1, "activity-1")
+	h.handleActivityTaskScheduled(2, "activity-2")
+	h.handleActivityTaskClosed("activity-2")
+	h.handleTimerStarted("timer-1")
+
+	require.Equal(t, PendingOperations{
+		Activities: []PendingActivityInfo{
+			{ActivityID: "activity-1", ActivityType: "activity-type", State: "Initiated"},
+		},
+		Timers: []PendingTimerInfo{
+			{TimerID: "timer-1", StartToFireTimeout: time.Minute, State: "Initiated"},
+		},
+		ChildWorkflows: []PendingChildWorkflowInfo{
+			{Domain: "child-domain", WorkflowID: "child-1", WorkflowType: "child-type", State: "DecisionSent"},
+		},
+		ExternalSignals: []PendingExternalWorkflowInfo{
+			{Domain: "external-domain", WorkflowID: "external-1", RunID: "run-1", SignalName: "signal-1", State: "DecisionSent"},
+		},
+		ExternalCancels: []PendingExternalWorkflowInfo{
+			{Domain: "external-domain", WorkflowID: "external-2", RunID: "run-2", State: "DecisionSent"},
+		},
+	}, h.pendingOperations())
+}
@@ -37,6 +37,7 @@ import (
 	m "go.uber.org/cadence/.gen/go/shared"
 	"go.uber.org/cadence/internal/common"
 	"go.uber.org/cadence/internal/common/metrics"
+	"go.uber.org/thriftrw/protocol"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -65,16 +66,16 @@ type (
 	}
 
 	scheduledActivity struct {
-		callback             resultHandler
-		waitForCancelRequest bool
-		handled              bool
+		callback         resultHandler
+		cancellationType CancellationType
+		handled          bool
 	}
 
 	scheduledChildWorkflow struct {
-		resultCallback      resultHandler
-		startedCallback     func(r WorkflowExecution, e error)
-		waitForCancellation bool
-		handled             bool
+		resultCallback   resultHandler
+		startedCallback  func(r WorkflowExecution, e error)
+		cancellationType CancellationType
+		handled          bool
 	}
 
 	scheduledCancellation struct {
@@ -320,6 +321,34 @@ func (wc *workflowEnvironmentImpl) updateWorkflowInfoWithSearchAttributes(attrib
 	wc.workflowInfo.SearchAttributes = mergeSearchAttributes(wc.workflowInfo.SearchAttributes, attributes)
 }
 
+func mergeMemo(current, upsert *shared.Memo) *shared.Memo {
+	if current == nil || len(current.Fields) == 0 {
+		if upsert == nil || len(upsert.Fields) == 0 {
+			return nil
+		}
+		current = &shared.Memo{
+			Fields: make(map[string][]byte),
+		}
+	}
+
+	fields := current.Fields
+	for k, v := range upsert.Fields {
+		fields[k] = v
+	}
+	return current
+}
+
+func copyMemo(memo *shared.Memo) *shared.Memo {
+	if memo == nil {
+		return nil
+	}
+	fields := make(map[string][]byte, len(memo.Fields))
+	for k, v := range memo.Fields {
+		fields[k] = v
+	}
+	return &shared.Memo{Fields: fields}
+}
+
 func mergeSearchAttributes(current, upsert *shared.SearchAttributes) *shared.SearchAttributes {
 	if current == nil || len(current.IndexedFields) == 0 {
 		if upsert == nil || len(upsert.IndexedFields) == 0 {
@@ -337,6 +366,17 @@ func mergeSearchAttributes(current, upsert *shared.SearchAttributes) *shared.Sea
 	return current
 }
 
+func copySearchAttributes(attributes *shared.SearchAttributes) *shared.SearchAttributes {
+	if attributes == nil {
+		return nil
+	}
+	fields := make(map[string][]byte, len(attributes.IndexedFields))
+	for k, v := range attributes.IndexedFields {
+		fields[k] = v
+	}
+	return &shared.SearchAttributes{IndexedFields: fields}
+}
+
 func validateAndSerializeSearchAttributes(attributes map[string]interface{}) (*shared.SearchAttributes, error) {
 	if len(attributes) == 0 {
 		return nil, errSearchAttributesNotSet
@@ -385,11 +425,11 @@ func (wc *workflowEnvironmentImpl) ExecuteChildWorkflow(
 		attributes.CronSchedule = common.StringPtr(params.cronSchedule)
 	}
 
-	decision := wc.decisionsHelper.startChildWorkflowExecution(attributes)
+	decision := wc.decisionsHelper.startChildWorkflowExecution(attributes, params.cancellationType)
 	decision.setData(&scheduledChildWorkflow{
-		resultCallback:      callback,
-		startedCallback:     startedHandler,
-		waitForCancellation: params.waitForCancellation,
+		resultCallback:   callback,
+		startedCallback:  startedHandler,
+		cancellationType: params.cancellationType,
 	})
 
 	wc.logger.Debug("ExecuteChildWorkflow",
@@ -461,10 +501,10 @@ func (wc *workflowEnvironmentImpl) ExecuteActivity(parameters executeActivityPar
 	scheduleTaskAttr.RetryPolicy = parameters.RetryPolicy
 	scheduleTaskAttr.Header = parameters.Header
 
-	decision := wc.decisionsHelper.scheduleActivityTask(scheduleTaskAttr)
+	decision := wc.decisionsHelper.scheduleActivityTask(scheduleTaskAttr, parameters.CancellationType)
 	decision.setData(&scheduledActivity{
-		callback:             callback,
-		waitForCancelRequest: parameters.WaitForCancellation,
+		callback:         callback,
+		cancellationType: parameters.CancellationType,
 	})
 
 	wc.logger.Debug("ExecuteActivity",
@@ -481,7 +521,7 @@ func (wc *workflowEnvironmentImpl) RequestCancelActivity(activityID string) {
 		return
 	}
 
-	if decision.isDone() || !activity.waitForCancelRequest {
+	if decision.isDone() || activity.cancellationType != CancellationTypeWaitCancellationCompleted {
 		activity.handle(nil, ErrCanceled)
 	}
 
@@ -921,12 +961,43 @@ func (weh *workflowExecutionEventHandlerImpl) ProcessEvent(
 	return nil
 }
 
+// updateHistoryStats accounts the event in the history length and size of the workflow info. It is called for every
+// event of the decision batch before the batch is processed, so workflow code observes the same values on replay.
+// The event is sized right away, so no reference to it is kept once the batch is processed.
+func (weh *workflowExecutionEventHandlerImpl) updateHistoryStats(event *m.HistoryEvent) {
+	weh.workflowInfo.HistoryLength++
+	weh.workflowInfo.historySize += historyEventSize(event)
+}
+
+// historyEventSize returns the size of the event as encoded by thrift, without buffering the encoded event.
+func historyEventSize(event *m.HistoryEvent) int64 {
+	value, err := event.ToWire()
+	if err != nil {
+		return 0
+	}
+	var size byteCounter
+	if err := protocol.Binary.Encode(value, &size); err != nil {
+		return 0
+	}
+	return int64(size)
+}
+
+// byteCounter is an io.Writer counting the bytes written to it
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
 func (weh *workflowExecutionEventHandlerImpl) ProcessQuery(queryType string, queryArgs []byte) ([]byte, error) {
 	switch queryType {
 	case QueryTypeStackTrace:
 		return weh.encodeArg(weh.StackTrace())
 	case QueryTypeOpenSessions:
 		return weh.encodeArg(weh.getOpenSessions())
+	case QueryTypePendingOperations:
+		return weh.encodeArg(weh.getPendingOperations())
 	default:
 		result, err := weh.queryHandler(queryType, queryArgs)
 		if err != nil {
@@ -946,6 +1017,12 @@ func (weh *workflowExecutionEventHandlerImpl) ProcessQuery(queryType string, que
 	}
 }
 
+func (weh *workflowExecutionEventHandlerImpl) getPendingOperations() PendingOperations {
+	result := weh.decisionsHelper.pendingOperations()
+	result.QueryHandlers, result.SignalHandlers = weh.workflowDefinition.HandlerNames()
+	return result
+}
+
 func (weh *workflowExecutionEventHandlerImpl) StackTrace() string {
 	return weh.workflowDefinition.StackTrace()
 }
@@ -1027,7 +1104,7 @@ func (weh *workflowExecutionEventHandlerImpl) handleActivityTaskCanceled(event *
 		return nil
 	}
 
-	if decision.isDone() || !activity.waitForCancelRequest {
+	if decision.isDone() || activity.cancellationType != CancellationTypeWaitCancellationCompleted {
 		// Clear this so we don't have a recursive call that while executing might call the cancel one.
 		details := newEncodedValues(event.ActivityTaskCanceledEventAttributes.Details, weh.GetDataConverter())
 		err := NewCanceledError(details)
@@ -0,0 +1,255 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/pborman/uuid"
+	s "go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/internal/common"
+	"go.uber.org/cadence/internal/common/backoff"
+)
+
+type resetPointType int
+
+const (
+	resetPointTypeUnspecified resetPointType = iota
+	resetPointTypeFirstDecisionCompleted
+	resetPointTypeLastDecisionCompleted
+	resetPointTypeBadBinary
+	resetPointTypeEventID
+)
+
+type (
+	// ResetPoint selects the decision a workflow execution is reset to. Use ResetPointFirstDecisionCompleted,
+	// ResetPointLastDecisionCompleted, ResetPointBadBinary or ResetPointEventID to create one.
+	ResetPoint struct {
+		pointType      resetPointType
+		binaryChecksum string
+		eventID        int64
+	}
+
+	// ResetWorkflowRequest is the request to ResetWorkflow
+	ResetWorkflowRequest struct {
+		// WorkflowID is a required field indicating the workflow which should be reset.
+		WorkflowID string
+
+		// RunID is an optional field used to identify a specific run of the workflow.
+		// If RunID is not provided the current run will be used.
+		RunID string
+
+		// Reason is an optional field recorded in the history of the reset run.
+		Reason string
+
+		// ResetPoint is a required field selecting the decision the run is reset to.
+		ResetPoint ResetPoint
+
+		// RequestID is an optional field used to deduplicate the reset on retries.
+		// If RequestID is not provided a random one will be used.
+		RequestID string
+	}
+
+	// ResetWorkflowsRequest is the request to ResetWorkflows
+	ResetWorkflowsRequest struct {
+		// Query is a required visibility query selecting the executions to reset,
+		// e.g. "WorkflowType = 'OrderWorkflow' AND CloseTime = missing".
+		Query string
+
+		// Reason is an optional field recorded in the history of every reset run.
+		Reason string
+
+		// ResetPoint is a required field selecting the decision every run is reset to.
+		ResetPoint ResetPoint
+
+		// Concurrency, RPS, PageSize, Checkpoint and ProgressHandler are optional fields with the same meaning
+		// as in BatchWorkflowRequest.
+		Concurrency     int
+		RPS             float64
+		PageSize        int32
+		Checkpoint      []byte
+		ProgressHandler func(progress BatchWorkflowProgress)
+	}
+)
+
+// ResetPointFirstDecisionCompleted resets the run to its first completed decision, replaying it almost from
+// the beginning.
+func ResetPointFirstDecisionCompleted() ResetPoint {
+	return ResetPoint{pointType: resetPointTypeFirstDecisionCompleted}
+}
+
+// ResetPointLastDecisionCompleted resets the run to its last completed decision, which is typically used to
+// unblock a workflow stuck on a failing decision.
+func ResetPointLastDecisionCompleted() ResetPoint {
+	return ResetPoint{pointType: resetPointTypeLastDecisionCompleted}
+}
+
+// ResetPointBadBinary resets the run to the last decision completed by a good binary, that is right before the
+// first decision completed by the worker binary with the given checksum. The reset point is taken from the auto
+// reset points the service records for the workflow, so it may belong to an earlier run of a continued-as-new chain.
+func ResetPointBadBinary(binaryChecksum string) ResetPoint {
+	return ResetPoint{pointType: resetPointTypeBadBinary, binaryChecksum: binaryChecksum}
+}
+
+// ResetPointEventID resets the run to the given DecisionTaskCompleted, DecisionTaskFailed or DecisionTaskTimedOut
+// event.
+func ResetPointEventID(eventID int64) ResetPoint {
+	return ResetPoint{pointType: resetPointTypeEventID, eventID: eventID}
+}
+
+func (p ResetPoint) String() string {
+	switch p.pointType {
+	case resetPointTypeFirstDecisionCompleted:
+		return "FirstDecisionCompleted"
+	case resetPointTypeLastDecisionCompleted:
+		return "LastDecisionCompleted"
+	case resetPointTypeBadBinary:
+		return fmt.Sprintf("BadBinary(%v)", p.binaryChecksum)
+	case resetPointTypeEventID:
+		return fmt.Sprintf("EventID(%v)", p.eventID)
+	default:
+		return "Unspecified"
+	}
+}
+
+// ResetWorkflow resets a workflow execution to the decision selected by request.ResetPoint. The current run of the
+// workflow is terminated and a new run replaying the history up to the reset point is started.
+// It returns the new run.
+// The errors it can return:
+//  - BadRequestError
+//  - InternalServiceError
+//  - EntityNotExistError
+func (wc *workflowClient) ResetWorkflow(ctx context.Context, request *ResetWorkflowRequest) (*WorkflowExecution, error) {
+	if request.WorkflowID == "" {
+		return nil, errors.New("workflowID is required")
+	}
+	runID, eventID, err := wc.resolveResetPoint(ctx, request.WorkflowID, request.RunID, request.ResetPoint)
+	if err != nil {
+		return nil, err
+	}
+	requestID := request.RequestID
+	if requestID == "" {
+		requestID = uuid.New()
+	}
+
+	resetRequest := &s.ResetWorkflowExecutionRequest{
+		Domain: common.StringPtr(wc.domain),
+		WorkflowExecution: &s.WorkflowExecution{
+			WorkflowId: common.StringPtr(request.WorkflowID),
+			RunId:      getRunID(runID),
+		},
+		Reason:                common.StringPtr(request.Reason),
+		DecisionFinishEventId: common.Int64Ptr(eventID),
+		RequestId:             common.StringPtr(requestID),
+	}
+
+	var response *s.ResetWorkflowExecutionResponse
+	err = backoff.Retry(ctx,
+		func() error {
+			var err1 error
+			tchCtx, cancel, opt := newChannelContext(ctx)
+			defer cancel()
+			response, err1 = wc.workflowService.ResetWorkflowExecution(tchCtx, resetRequest, opt...)
+			return err1
+		}, createDynamicServiceRetryPolicy(ctx), isServiceTransientError)
+	if err != nil {
+		return nil, err
+	}
+	return &WorkflowExecution{ID: request.WorkflowID, RunID: response.GetRunId()}, nil
+}
+
+// ResetWorkflows resets every workflow execution matching request.Query to request.ResetPoint. It is BatchWorkflow
+// with a BatchReset operation, so the resets are bounded and rate limited, failures are reported per execution and
+// an interrupted call can be resumed from the returned checkpoint.
+func (wc *workflowClient) ResetWorkflows(ctx context.Context, request *ResetWorkflowsRequest) (*BatchWorkflowResponse, error) {
+	if request.ResetPoint.pointType == resetPointTypeUnspecified {
+		return nil, errors.New("reset point is required")
+	}
+	return wc.intercepted().BatchWorkflow(ctx, &BatchWorkflowRequest{
+		Query:           request.Query,
+		Operation:       BatchReset(request.Reason, request.ResetPoint),
+		Concurrency:     request.Concurrency,
+		RPS:             request.RPS,
+		PageSize:        request.PageSize,
+		Checkpoint:      request.Checkpoint,
+		ProgressHandler: request.ProgressHandler,
+	})
+}
+
+// resolveResetPoint returns the run and the decision finish event ID a reset to point applies to.
+func (wc *workflowClient) resolveResetPoint(ctx context.Context, workflowID, runID string, point ResetPoint) (string, int64, error) {
+	switch point.pointType {
+	case resetPointTypeEventID:
+		if point.eventID <= 0 {
+			return "", 0, fmt.Errorf("invalid reset event ID %v", point.eventID)
+		}
+		return runID, point.eventID, nil
+	case resetPointTypeFirstDecisionCompleted, resetPointTypeLastDecisionCompleted:
+		if runID == "" {
+			// pin the current run so that the reset applies to the history the event ID was taken from
+			resp, err := wc.intercepted().DescribeWorkflowExecution(ctx, workflowID, "")
+			if err != nil {
+				return "", 0, err
+			}
+			if info := resp.WorkflowExecutionInfo; info != nil && info.Execution != nil {
+				runID = info.Execution.GetRunId()
+			}
+		}
+		var eventID int64
+		iter := wc.intercepted().GetWorkflowHistory(ctx, workflowID, runID, false, s.HistoryEventFilterTypeAllEvent)
+		for iter.HasNext() {
+			event, err := iter.Next()
+			if err != nil {
+				return "", 0, err
+			}
+			if event.GetEventType() != s.EventTypeDecisionTaskCompleted {
+				continue
+			}
+			eventID = event.GetEventId()
+			if point.pointType == resetPointTypeFirstDecisionCompleted {
+				break
+			}
+		}
+		if eventID == 0 {
+			return "", 0, fmt.Errorf("no completed decision found in workflow %v run %v", workflowID, runID)
+		}
+		return runID, eventID, nil
+	case resetPointTypeBadBinary:
+		resp, err := wc.intercepted().DescribeWorkflowExecution(ctx, workflowID, runID)
+		if err != nil {
+			return "", 0, err
+		}
+		if info := resp.WorkflowExecutionInfo; info != nil && info.AutoResetPoints != nil {
+			for _, p := range info.AutoResetPoints.Points {
+				if p.GetBinaryChecksum() == point.binaryChecksum {
+					if !p.GetResettable() {
+						return "", 0, fmt.Errorf("reset point of binary %v is not resettable", point.binaryChecksum)
+					}
+					return p.GetRunId(), p.GetFirstDecisionCompletedId(), nil
+				}
+			}
+		}
+		return "", 0, fmt.Errorf("no reset point found for binary %v in workflow %v", point.binaryChecksum, workflowID)
+	default:
+		return "", 0, errors.New("reset point is required")
+	}
+}
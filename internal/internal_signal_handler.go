@@ -0,0 +1,154 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"go.uber.org/cadence/internal/common/metrics"
+	"go.uber.org/zap"
+)
+
+type (
+	// signalDispatcher delivers the signals of a workflow execution to the handlers registered with SetSignalHandler.
+	// Signals are handled one at a time in the order they were received.
+	signalDispatcher struct {
+		handlers map[string]interface{}
+		pending  []pendingSignal
+	}
+
+	pendingSignal struct {
+		name string
+		data []byte
+	}
+)
+
+func (wc *workflowEnvironmentInterceptor) SetSignalHandler(ctx Context, signalName string, handler interface{}) error {
+	if err := validateSignalHandlerFn(handler); err != nil {
+		return err
+	}
+
+	if wc.signals == nil {
+		wc.signals = &signalDispatcher{handlers: make(map[string]interface{})}
+		dc := getDataConverterFromWorkflowContext(ctx)
+		// signals received while the workflow cleans up after a cancellation are still delivered, so the
+		// dispatcher runs until the workflow completes
+		dispatcherCtx, _ := NewDisconnectedContext(ctx)
+		GoNamed(dispatcherCtx, "signal-dispatcher", func(ctx Context) {
+			for {
+				if err := Await(ctx, wc.signals.hasPending); err != nil {
+					return
+				}
+				s := wc.signals.pending[0]
+				wc.signals.pending = wc.signals.pending[1:]
+				wc.signals.handle(ctx, s, dc)
+			}
+		})
+	}
+
+	if _, ok := wc.signals.handlers[signalName]; !ok {
+		// signals received before the handler was registered are buffered in the signal channel
+		if ch, ok := getWorkflowEnvOptions(ctx).signalChannels[signalName]; ok {
+			for {
+				v, ok, _ := ch.(*channelImpl).receiveAsyncImpl(nil)
+				if !ok {
+					break
+				}
+				wc.signals.pending = append(wc.signals.pending, pendingSignal{name: signalName, data: v.([]byte)})
+			}
+		}
+	}
+	wc.signals.handlers[signalName] = handler
+	return nil
+}
+
+func (wc *workflowEnvironmentInterceptor) GetUnhandledSignalNames(ctx Context) []string {
+	names := make(map[string]bool)
+	for _, name := range getWorkflowEnvOptions(ctx).getUnhandledSignals() {
+		names[name] = true
+	}
+	if wc.signals != nil {
+		for _, s := range wc.signals.pending {
+			names[s.name] = true
+		}
+	}
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// accept queues the signal if a handler is registered for it.
+func (d *signalDispatcher) accept(name string, data []byte) bool {
+	if d == nil {
+		return false
+	}
+	if _, ok := d.handlers[name]; !ok {
+		return false
+	}
+	d.pending = append(d.pending, pendingSignal{name: name, data: data})
+	return true
+}
+
+func (d *signalDispatcher) hasPending() bool {
+	return len(d.pending) > 0
+}
+
+func (d *signalDispatcher) handle(ctx Context, s pendingSignal, dc DataConverter) {
+	fnValue := reflect.ValueOf(d.handlers[s.name])
+	args := []reflect.Value{reflect.ValueOf(ctx)}
+	if fnValue.Type().NumIn() == 2 {
+		arg := reflect.New(fnValue.Type().In(1))
+		if err := dc.FromData(s.data, arg.Interface()); err != nil {
+			// like a corrupt signal received on a channel, the signal is dropped
+			env := getWorkflowEnvironment(ctx)
+			env.GetLogger().Error(fmt.Sprintf("Corrupt signal received for handler %s. Error deserializing", s.name), zap.Error(err))
+			env.GetMetricsScope().Counter(metrics.CorruptedSignalsCounter).Inc(1)
+			return
+		}
+		args = append(args, arg.Elem())
+	}
+	fnValue.Call(args)
+}
+
+func validateSignalHandlerFn(handler interface{}) error {
+	fnType := reflect.TypeOf(handler)
+	if fnType == nil || fnType.Kind() != reflect.Func {
+		return fmt.Errorf("signal handler must be function but was %v", fnType)
+	}
+	if fnType.NumIn() < 1 || fnType.NumIn() > 2 {
+		return fmt.Errorf(
+			"signal handler must accept workflow.Context and at most one payload parameter, but found %v input parameters",
+			fnType.NumIn(),
+		)
+	}
+	if !isWorkflowContext(fnType.In(0)) {
+		return fmt.Errorf("expected first argument of signal handler to be workflow.Context but found %s", fnType.In(0))
+	}
+	if fnType.NumOut() != 0 {
+		return fmt.Errorf("signal handler must not return values, but found %v return values", fnType.NumOut())
+	}
+	return nil
+}
@@ -132,18 +132,19 @@ type (
 
 	// activityTaskHandlerImpl is the implementation of ActivityTaskHandler
 	activityTaskHandlerImpl struct {
-		taskListName       string
-		identity           string
-		service            workflowserviceclient.Interface
-		metricsScope       *metrics.TaggedScope
-		logger             *zap.Logger
-		userContext        context.Context
-		registry           *registry
-		activityProvider   activityProvider
-		dataConverter      DataConverter
-		workerStopCh       <-chan struct{}
-		contextPropagators []ContextPropagator
-		tracer             opentracing.Tracer
+		taskListName         string
+		identity             string
+		service              workflowserviceclient.Interface
+		metricsScope         *metrics.TaggedScope
+		logger               *zap.Logger
+		userContext          context.Context
+		registry             *registry
+		activityProvider     activityProvider
+		dataConverter        DataConverter
+		workerStopCh         <-chan struct{}
+		contextPropagators   []ContextPropagator
+		tracer               opentracing.Tracer
+		activityInterceptors []ActivityInterceptorFactory
 	}
 
 	// history wrapper method to help information about events.
@@ -328,6 +329,9 @@ OrderEvents:
 		}
 
 		eh.nextEventID++
+		if eh.eventsHandler != nil {
+			eh.eventsHandler.updateHistoryStats(event)
+		}
 
 		switch event.GetEventType() {
 		case s.EventTypeDecisionTaskStarted:
@@ -629,6 +633,7 @@ func (wth *workflowTaskHandlerImpl) createWorkflowContext(task *s.PollForDecisio
 		ParentWorkflowExecution:             parentWorkflowExecution,
 		Memo:                                attributes.Memo,
 		SearchAttributes:                    attributes.SearchAttributes,
+		dataConverter:                       wth.dataConverter,
 	}
 
 	wfStartTime := time.Unix(0, h.Events[0].GetTimestamp())
@@ -1487,6 +1492,14 @@ func (wth *workflowTaskHandlerImpl) completeWorkflow(
 	} else if contErr, ok := workflowContext.err.(*ContinueAsNewError); ok {
 		// Continue as new error.
 		metricsScope.Counter(metrics.WorkflowContinueAsNewCounter).Inc(1)
+		memo := workflowContext.workflowInfo.Memo
+		if contErr.memo != nil {
+			memo = contErr.memo
+		}
+		searchAttributes := workflowContext.workflowInfo.SearchAttributes
+		if contErr.searchAttributes != nil {
+			searchAttributes = contErr.searchAttributes
+		}
 		closeDecision = createNewDecision(s.DecisionTypeContinueAsNewWorkflowExecution)
 		closeDecision.ContinueAsNewWorkflowExecutionDecisionAttributes = &s.ContinueAsNewWorkflowExecutionDecisionAttributes{
 			WorkflowType:                        workflowTypePtr(*contErr.params.workflowType),
@@ -1494,9 +1507,10 @@ func (wth *workflowTaskHandlerImpl) completeWorkflow(
 			TaskList:                            common.TaskListPtr(s.TaskList{Name: contErr.params.taskListName}),
 			ExecutionStartToCloseTimeoutSeconds: contErr.params.executionStartToCloseTimeoutSeconds,
 			TaskStartToCloseTimeoutSeconds:      contErr.params.taskStartToCloseTimeoutSeconds,
+			RetryPolicy:                         contErr.retryPolicy,
 			Header:                              contErr.params.header,
-			Memo:                                workflowContext.workflowInfo.Memo,
-			SearchAttributes:                    workflowContext.workflowInfo.SearchAttributes,
+			Memo:                                memo,
+			SearchAttributes:                    searchAttributes,
 		}
 	} else if workflowContext.err != nil {
 		// Workflow failures
@@ -1596,18 +1610,19 @@ func newActivityTaskHandlerWithCustomProvider(
 	activityProvider activityProvider,
 ) ActivityTaskHandler {
 	return &activityTaskHandlerImpl{
-		taskListName:       params.TaskList,
-		identity:           params.Identity,
-		service:            service,
-		logger:             params.Logger,
-		metricsScope:       metrics.NewTaggedScope(params.MetricsScope),
-		userContext:        params.UserContext,
-		registry:           registry,
-		activityProvider:   activityProvider,
-		dataConverter:      params.DataConverter,
-		workerStopCh:       params.WorkerStopChannel,
-		contextPropagators: params.ContextPropagators,
-		tracer:             params.Tracer,
+		taskListName:         params.TaskList,
+		identity:             params.Identity,
+		service:              service,
+		logger:               params.Logger,
+		metricsScope:         metrics.NewTaggedScope(params.MetricsScope),
+		userContext:          params.UserContext,
+		registry:             registry,
+		activityProvider:     activityProvider,
+		dataConverter:        params.DataConverter,
+		workerStopCh:         params.WorkerStopChannel,
+		contextPropagators:   params.ContextPropagators,
+		tracer:               params.Tracer,
+		activityInterceptors: params.ActivityInterceptors,
 	}
 }
 
@@ -1621,6 +1636,7 @@ type cadenceInvoker struct {
 	hbBatchEndTimer       *time.Timer // Whether we started a batch of operations that need to be reported in the cycle. This gets started on a user call.
 	lastDetailsToReport   *[]byte
 	closeCh               chan struct{}
+	closed                bool // Set by Close, heartbeats recorded after it are dropped.
 	workerStopChannel     <-chan struct{}
 }
 
@@ -1628,6 +1644,10 @@ func (i *cadenceInvoker) Heartbeat(details []byte, skipBatching bool) error {
 	i.Lock()
 	defer i.Unlock()
 
+	if i.closed {
+		// The activity has returned, its heartbeats are no longer accepted.
+		return nil
+	}
 	if i.hbBatchEndTimer != nil && !skipBatching {
 		// If we have started batching window, keep track of last reported progress.
 		i.lastDetailsToReport = &details
@@ -1718,6 +1738,7 @@ func (i *cadenceInvoker) internalHeartBeat(details []byte) (bool, error) {
 func (i *cadenceInvoker) Close(flushBufferedHeartbeat bool) {
 	i.Lock()
 	defer i.Unlock()
+	i.closed = true
 	close(i.closeCh)
 	if i.hbBatchEndTimer != nil {
 		i.hbBatchEndTimer.Stop()
@@ -1739,7 +1760,7 @@ func newServiceInvoker(
 	cancelHandler func(),
 	heartBeatTimeoutInSec int32,
 	workerStopChannel <-chan struct{},
-) ServiceInvoker {
+) *cadenceInvoker {
 	return &cadenceInvoker{
 		taskToken:             taskToken,
 		identity:              identity,
@@ -1775,7 +1796,7 @@ func (ath *activityTaskHandlerImpl) Execute(taskList string, t *s.PollForActivit
 	workflowType := t.WorkflowType.GetName()
 	activityType := t.ActivityType.GetName()
 	metricsScope := getMetricsScopeForActivity(ath.metricsScope, workflowType, activityType)
-	ctx := WithActivityTask(canCtx, t, taskList, invoker, ath.logger, metricsScope, ath.dataConverter, ath.workerStopCh, ath.contextPropagators, ath.tracer)
+	ctx := WithActivityTask(canCtx, t, taskList, invoker, ath.logger, metricsScope, ath.dataConverter, ath.workerStopCh, ath.contextPropagators, ath.tracer, ath.activityInterceptors)
 
 	activityImplementation := ath.getActivity(activityType)
 	if activityImplementation == nil {
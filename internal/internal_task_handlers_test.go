@@ -22,11 +22,13 @@
 package internal
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -39,6 +41,7 @@ import (
 	"go.uber.org/cadence/.gen/go/cadence/workflowservicetest"
 	s "go.uber.org/cadence/.gen/go/shared"
 	"go.uber.org/cadence/internal/common"
+	"go.uber.org/thriftrw/protocol"
 	"go.uber.org/zap"
 )
 
@@ -76,6 +79,12 @@ func registerWorkflows(r *registry) {
 		getWorkflowInfoWorkflowFunc,
 		RegisterWorkflowOptions{Name: "GetWorkflowInfoWorkflow"},
 	)
+	r.RegisterWorkflowWithOptions(
+		func(ctx Context) (int64, error) {
+			return GetWorkflowInfo(ctx).GetHistorySize(), nil
+		},
+		RegisterWorkflowOptions{Name: "HistorySizeWorkflow"},
+	)
 	r.RegisterWorkflowWithOptions(
 		querySignalWorkflowFunc,
 		RegisterWorkflowOptions{Name: "QuerySignalWorkflow"},
@@ -516,6 +525,43 @@ func (t *TaskHandlersTestSuite) TestWorkflowTask_QueryWorkflow_NonSticky() {
 	t.Contains(*queryResp.ErrorMessage, "unknown queryType")
 }
 
+func (t *TaskHandlersTestSuite) TestWorkflowTask_QueryPendingOperations() {
+	taskList := "tl1"
+	testEvents := []*s.HistoryEvent{
+		createTestEventWorkflowExecutionStarted(1, &s.WorkflowExecutionStartedEventAttributes{TaskList: &s.TaskList{Name: &taskList}}),
+		createTestEventDecisionTaskScheduled(2, &s.DecisionTaskScheduledEventAttributes{TaskList: &s.TaskList{Name: &taskList}}),
+		createTestEventDecisionTaskStarted(3),
+		createTestEventDecisionTaskCompleted(4, &s.DecisionTaskCompletedEventAttributes{ScheduledEventId: common.Int64Ptr(2)}),
+		createTestEventActivityTaskScheduled(5, &s.ActivityTaskScheduledEventAttributes{
+			ActivityId:   common.StringPtr("0"),
+			ActivityType: &s.ActivityType{Name: common.StringPtr("Greeter_Activity")},
+			TaskList:     &s.TaskList{Name: &taskList},
+		}),
+	}
+	params := workerExecutionParameters{
+		TaskList: taskList,
+		Identity: "test-id-1",
+		Logger:   t.logger,
+	}
+
+	task := createQueryTask(testEvents, 5, "HelloWorld_Workflow", QueryTypePendingOperations)
+	taskHandler := newWorkflowTaskHandler(testDomain, params, nil, t.registry)
+	response, err := taskHandler.ProcessWorkflowTask(&workflowTask{task: task}, nil)
+	t.NoError(err)
+	queryResp, ok := response.(*s.RespondQueryTaskCompletedRequest)
+	t.True(ok)
+	t.Nil(queryResp.ErrorMessage)
+
+	var result PendingOperations
+	t.NoError(newEncodedValue(queryResp.QueryResult, nil).Get(&result))
+	t.Equal(PendingOperations{
+		Activities: []PendingActivityInfo{
+			{ActivityID: "0", ActivityType: "Greeter_Activity", State: "Initiated"},
+		},
+		QueryHandlers: []string{queryType},
+	}, result)
+}
+
 func (t *TaskHandlersTestSuite) verifyQueryResult(response interface{}, expectedResult string) {
 	t.NotNil(response)
 	queryResp, ok := response.(*s.RespondQueryTaskCompletedRequest)
@@ -719,6 +765,90 @@ func (t *TaskHandlersTestSuite) testSideEffectDeferHelper(disableSticky bool) {
 	t.EqualValues(0, getWorkflowCache().Size())
 }
 
+func (t *TaskHandlersTestSuite) TestWorkflowTask_NewRandomAndNewUUIDReplay() {
+	var observed [][]string
+	workflowFunc := func(ctx Context) ([]string, error) {
+		values := []string{fmt.Sprint(NewRandom(ctx).Int63()), NewUUID(ctx), fmt.Sprint(NewRandom(ctx).Int63())}
+		observed = append(observed, values)
+		if err := Sleep(ctx, time.Second); err != nil {
+			return nil, err
+		}
+		return append(values, NewUUID(ctx)), nil
+	}
+	workflowName := "NewRandomAndNewUUIDWorkflow"
+	t.registry.RegisterWorkflowWithOptions(
+		workflowFunc,
+		RegisterWorkflowOptions{Name: workflowName},
+	)
+
+	taskList := "taskList"
+	testEvents := []*s.HistoryEvent{
+		createTestEventWorkflowExecutionStarted(1, &s.WorkflowExecutionStartedEventAttributes{TaskList: &s.TaskList{Name: &taskList}}),
+		createTestEventDecisionTaskScheduled(2, &s.DecisionTaskScheduledEventAttributes{TaskList: &s.TaskList{Name: &taskList}}),
+		createTestEventDecisionTaskStarted(3),
+	}
+	params := workerExecutionParameters{
+		TaskList:               taskList,
+		Identity:               "test-id-1",
+		Logger:                 zap.NewNop(),
+		DisableStickyExecution: true,
+	}
+	taskHandler := newWorkflowTaskHandler(testDomain, params, nil, t.registry)
+
+	// first decision task records the seed once, however many values are drawn
+	request, err := taskHandler.ProcessWorkflowTask(&workflowTask{task: createWorkflowTask(testEvents, 0, workflowName)}, nil)
+	t.NoError(err)
+	response := request.(*s.RespondDecisionTaskCompletedRequest)
+	t.Equal(2, len(response.Decisions))
+	t.Equal(s.DecisionTypeRecordMarker, response.Decisions[0].GetDecisionType())
+	markerAttributes := response.Decisions[0].RecordMarkerDecisionAttributes
+	t.Equal(sideEffectMarkerName, markerAttributes.GetMarkerName())
+	t.Equal(s.DecisionTypeStartTimer, response.Decisions[1].GetDecisionType())
+	timerID := response.Decisions[1].StartTimerDecisionAttributes.GetTimerId()
+
+	// second decision task replays the first one from scratch and must not record the seed again
+	testEvents = append(testEvents,
+		createTestEventDecisionTaskCompleted(4, &s.DecisionTaskCompletedEventAttributes{ScheduledEventId: common.Int64Ptr(2)}),
+		&s.HistoryEvent{
+			EventId:   common.Int64Ptr(5),
+			EventType: common.EventTypePtr(s.EventTypeMarkerRecorded),
+			MarkerRecordedEventAttributes: &s.MarkerRecordedEventAttributes{
+				MarkerName:                   markerAttributes.MarkerName,
+				Details:                      markerAttributes.Details,
+				DecisionTaskCompletedEventId: common.Int64Ptr(4),
+			},
+		},
+		&s.HistoryEvent{
+			EventId:   common.Int64Ptr(6),
+			EventType: common.EventTypePtr(s.EventTypeTimerStarted),
+			TimerStartedEventAttributes: &s.TimerStartedEventAttributes{
+				TimerId:                      common.StringPtr(timerID),
+				DecisionTaskCompletedEventId: common.Int64Ptr(4),
+			},
+		},
+		&s.HistoryEvent{
+			EventId:                   common.Int64Ptr(7),
+			EventType:                 common.EventTypePtr(s.EventTypeTimerFired),
+			TimerFiredEventAttributes: &s.TimerFiredEventAttributes{TimerId: common.StringPtr(timerID)},
+		},
+		createTestEventDecisionTaskScheduled(8, &s.DecisionTaskScheduledEventAttributes{TaskList: &s.TaskList{Name: &taskList}}),
+		createTestEventDecisionTaskStarted(9),
+	)
+	request, err = taskHandler.ProcessWorkflowTask(&workflowTask{task: createWorkflowTask(testEvents, 3, workflowName)}, nil)
+	t.NoError(err)
+	response = request.(*s.RespondDecisionTaskCompletedRequest)
+	t.Equal(1, len(response.Decisions))
+	t.Equal(s.DecisionTypeCompleteWorkflowExecution, response.Decisions[0].GetDecisionType())
+
+	t.Equal(2, len(observed))
+	t.Equal(observed[0], observed[1])
+	var result []string
+	t.NoError(getDefaultDataConverter().FromData(response.Decisions[0].CompleteWorkflowExecutionDecisionAttributes.Result, &result))
+	t.Equal(4, len(result))
+	t.Equal(observed[0], result[:3])
+	t.NotEqual(result[1], result[3])
+}
+
 func (t *TaskHandlersTestSuite) TestWorkflowTask_NondeterministicDetection() {
 	taskList := "taskList"
 	testEvents := []*s.HistoryEvent{
@@ -858,6 +988,10 @@ func (t *TaskHandlersTestSuite) TestGetWorkflowInfo() {
 	workflowType := "GetWorkflowInfoWorkflow"
 	lastCompletionResult, err := getDefaultDataConverter().ToData("lastCompletionData")
 	t.NoError(err)
+	memo, err := getWorkflowMemo(map[string]interface{}{"owner": "test-owner"}, getDefaultDataConverter())
+	t.NoError(err)
+	searchAttributes, err := serializeSearchAttributes(map[string]interface{}{"CustomIntField": 7})
+	t.NoError(err)
 	startedEventAttributes := &s.WorkflowExecutionStartedEventAttributes{
 		Input:                               lastCompletionResult,
 		TaskList:                            &s.TaskList{Name: &taskList},
@@ -869,6 +1003,8 @@ func (t *TaskHandlersTestSuite) TestGetWorkflowInfo() {
 		ExecutionStartToCloseTimeoutSeconds: &executionTimeout,
 		TaskStartToCloseTimeoutSeconds:      &taskTimeout,
 		LastCompletionResult:                lastCompletionResult,
+		Memo:                                memo,
+		SearchAttributes:                    searchAttributes,
 	}
 	testEvents := []*s.HistoryEvent{
 		createTestEventWorkflowExecutionStarted(1, startedEventAttributes),
@@ -904,6 +1040,51 @@ func (t *TaskHandlersTestSuite) TestGetWorkflowInfo() {
 	t.EqualValues(taskTimeout, result.TaskStartToCloseTimeoutSeconds)
 	t.EqualValues(workflowType, result.WorkflowType.Name)
 	t.EqualValues(testDomain, result.Domain)
+	t.EqualValues(3, result.HistoryLength)
+	var owner string
+	t.NoError(result.GetMemo()["owner"].Get(&owner))
+	t.Equal("test-owner", owner)
+	var intField int
+	t.NoError(result.GetSearchAttributes()["CustomIntField"].Get(&intField))
+	t.Equal(7, intField)
+}
+
+func (t *TaskHandlersTestSuite) TestGetWorkflowInfo_HistorySize() {
+	taskList := "taskList"
+	testEvents := []*s.HistoryEvent{
+		createTestEventWorkflowExecutionStarted(1, &s.WorkflowExecutionStartedEventAttributes{
+			TaskList: &s.TaskList{Name: &taskList},
+			Input:    []byte("input"),
+		}),
+		createTestEventDecisionTaskScheduled(2, &s.DecisionTaskScheduledEventAttributes{TaskList: &s.TaskList{Name: &taskList}}),
+		createTestEventDecisionTaskStarted(3),
+	}
+	var historySize int64
+	for _, event := range testEvents {
+		value, err := event.ToWire()
+		t.NoError(err)
+		var buf bytes.Buffer
+		t.NoError(protocol.Binary.Encode(value, &buf))
+		historySize += int64(buf.Len())
+	}
+	task := createWorkflowTask(testEvents, 3, "HistorySizeWorkflow")
+	params := workerExecutionParameters{
+		TaskList:                       taskList,
+		Identity:                       "test-id-1",
+		Logger:                         zap.NewNop(),
+		NonDeterministicWorkflowPolicy: NonDeterministicWorkflowPolicyBlockWorkflow,
+	}
+
+	taskHandler := newWorkflowTaskHandler(testDomain, params, nil, t.registry)
+	request, err := taskHandler.ProcessWorkflowTask(&workflowTask{task: task}, nil)
+	t.NoError(err)
+	r, ok := request.(*s.RespondDecisionTaskCompletedRequest)
+	t.True(ok)
+	t.EqualValues(s.DecisionTypeCompleteWorkflowExecution, r.Decisions[0].GetDecisionType())
+	var result int64
+	t.NoError(getDefaultDataConverter().FromData(r.Decisions[0].CompleteWorkflowExecutionDecisionAttributes.Result, &result))
+	t.True(historySize > 0)
+	t.Equal(historySize, result)
 }
 
 func (t *TaskHandlersTestSuite) TestConsistentQuery_InvalidQueryTask() {
@@ -1226,6 +1407,24 @@ func (t *TaskHandlersTestSuite) TestHeartBeat_NilResponseWithDomainNotActiveErro
 	t.True(called)
 }
 
+func (t *TaskHandlersTestSuite) TestHeartBeat_AfterClose() {
+	mockCtrl := gomock.NewController(t.T())
+	defer mockCtrl.Finish()
+	// no heartbeat reaches the service once the invoker is closed
+	mockService := workflowservicetest.NewMockClient(mockCtrl)
+
+	cadenceInvoker := newServiceInvoker(
+		nil,
+		"Test_Cadence_Invoker",
+		mockService,
+		func() {},
+		0,
+		make(chan struct{}))
+	cadenceInvoker.Close(false)
+
+	t.NoError(cadenceInvoker.Heartbeat([]byte("progress"), false))
+}
+
 type testActivityDeadline struct {
 	logger *zap.Logger
 	d      time.Duration
@@ -1367,6 +1566,61 @@ func (t *TaskHandlersTestSuite) TestActivityExecutionWorkerStop() {
 	t.NotNil(r)
 }
 
+func activityWithoutHeartbeat(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(1200 * time.Millisecond):
+		return nil
+	}
+}
+
+func (t *TaskHandlersTestSuite) TestActivityExecutionAutoHeartbeat() {
+	registry := t.registry
+	registry.RegisterActivityWithOptions(
+		activityWithoutHeartbeat,
+		RegisterActivityOptions{Name: "autoHeartbeat", EnableAutoHeartbeat: true},
+	)
+
+	mockCtrl := gomock.NewController(t.T())
+	mockService := workflowservicetest.NewMockClient(mockCtrl)
+	var heartbeats int32
+	mockService.EXPECT().RecordActivityTaskHeartbeat(gomock.Any(), gomock.Any(), callOptions...).
+		Do(func(ctx interface{}, request *s.RecordActivityTaskHeartbeatRequest, opt1, opt2, opt3 interface{}) {
+			t.Equal([]byte("progress"), request.Details)
+			atomic.AddInt32(&heartbeats, 1)
+		}).Return(&s.RecordActivityTaskHeartbeatResponse{}, nil).MinTimes(1)
+
+	wep := workerExecutionParameters{
+		Logger:        t.logger,
+		DataConverter: getDefaultDataConverter(),
+		Tracer:        opentracing.NoopTracer{},
+	}
+	activityHandler := newActivityTaskHandler(mockService, wep, registry)
+	pats := &s.PollForActivityTaskResponse{
+		TaskToken: []byte("token"),
+		WorkflowExecution: &s.WorkflowExecution{
+			WorkflowId: common.StringPtr("wID"),
+			RunId:      common.StringPtr("rID")},
+		ActivityType:                  &s.ActivityType{Name: common.StringPtr("autoHeartbeat")},
+		ActivityId:                    common.StringPtr(uuid.New()),
+		ScheduledTimestamp:            common.Int64Ptr(time.Now().UnixNano()),
+		ScheduleToCloseTimeoutSeconds: common.Int32Ptr(5),
+		StartedTimestamp:              common.Int64Ptr(time.Now().UnixNano()),
+		StartToCloseTimeoutSeconds:    common.Int32Ptr(5),
+		HeartbeatTimeoutSeconds:       common.Int32Ptr(1),
+		HeartbeatDetails:              []byte("progress"),
+		WorkflowType: &s.WorkflowType{
+			Name: common.StringPtr("wType"),
+		},
+		WorkflowDomain: common.StringPtr("domain"),
+	}
+	r, err := activityHandler.Execute(tasklist, pats)
+	t.NoError(err)
+	t.IsType(&s.RespondActivityTaskCompletedRequest{}, r)
+	t.True(atomic.LoadInt32(&heartbeats) >= 1)
+}
+
 func Test_NonDeterministicCheck(t *testing.T) {
 	decisionTypes := s.DecisionType_Values()
 	require.Equal(t, 13, len(decisionTypes), "If you see this error, you are adding new decision type. "+
@@ -122,6 +122,7 @@ type (
 		dataConverter      DataConverter
 		contextPropagators []ContextPropagator
 		tracer             opentracing.Tracer
+		interceptors       []ActivityInterceptorFactory
 	}
 
 	localActivityResult struct {
@@ -419,6 +420,7 @@ func newLocalActivityPoller(params workerExecutionParameters, laTunnel *localAct
 		dataConverter:      params.DataConverter,
 		contextPropagators: params.ContextPropagators,
 		tracer:             params.Tracer,
+		interceptors:       params.ActivityInterceptors,
 	}
 	return &localActivityTaskPoller{
 		basePoller:   basePoller{shutdownC: params.WorkerStopChannel},
@@ -480,6 +482,7 @@ func (lath *localActivityTaskHandler) executeLocalActivityTask(task *localActivi
 		isLocalActivity:   true,
 		dataConverter:     lath.dataConverter,
 		attempt:           task.attempt,
+		interceptors:      lath.interceptors,
 	})
 
 	// propagate context information into the local activity activity context from the headers
@@ -0,0 +1,232 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	// updateSignalName is the signal delivering update requests to the workflow
+	updateSignalName = "__cadence_update"
+	// updateQueryType is the query returning the outcome of an update
+	updateQueryType = "__cadence_update_outcome"
+	// maxCompletedUpdateOutcomes is the number of completed update outcomes kept for deduplication and queries
+	maxCompletedUpdateOutcomes = 1000
+)
+
+type (
+	// UpdateHandlerOptions configures an update handler registered with SetUpdateHandler.
+	UpdateHandlerOptions struct {
+		// Optional: Validator is invoked with the update arguments before the handler. If it returns an error the
+		// update is rejected: the handler is not invoked and the caller receives an UpdateRejectedError.
+		// The validator must be a function accepting the same parameters as the handler and returning an error.
+		// Like a query handler, it must not block or mutate the workflow state.
+		Validator interface{}
+	}
+
+	// updateRequest is the payload of the update signal
+	updateRequest struct {
+		ID   string
+		Name string
+		Args []byte
+	}
+
+	// updateOutcome is the state of an update returned by the update query
+	updateOutcome struct {
+		// Unknown is set when the workflow has no state for the update: it has not received the update yet or the
+		// outcome was evicted after completion
+		Unknown   bool
+		Completed bool
+		Rejected  bool
+		Result    []byte
+		Reason    string
+		Details   []byte
+	}
+
+	updateHandler struct {
+		ctx       Context
+		name      string
+		fn        interface{}
+		validator interface{}
+	}
+
+	// updateDispatcher receives the update requests of a workflow execution and runs their handlers
+	updateDispatcher struct {
+		handlers map[string]*updateHandler
+		outcomes map[string]*updateOutcome
+		// IDs of the completed updates in the order of completion, the oldest ones are evicted from outcomes
+		completed []string
+	}
+)
+
+func (wc *workflowEnvironmentInterceptor) SetUpdateHandler(ctx Context, updateName string, handler interface{}, options UpdateHandlerOptions) error {
+	if strings.HasPrefix(updateName, "__") {
+		return errors.New("updateName starts with '__' is reserved for internal use")
+	}
+	h := &updateHandler{ctx: ctx, name: updateName, fn: handler, validator: options.Validator}
+	if err := h.validateHandlerFn(); err != nil {
+		return err
+	}
+
+	if wc.updates == nil {
+		wc.updates = &updateDispatcher{
+			handlers: make(map[string]*updateHandler),
+			outcomes: make(map[string]*updateOutcome),
+		}
+		if err := setQueryHandler(ctx, updateQueryType, wc.updates.getOutcome); err != nil {
+			return err
+		}
+		ch := wc.GetSignalChannel(ctx, updateSignalName)
+		dc := getDataConverterFromWorkflowContext(ctx)
+		GoNamed(ctx, "update-dispatcher", func(ctx Context) {
+			for {
+				var request updateRequest
+				ch.Receive(ctx, &request)
+				wc.updates.dispatch(request, dc)
+			}
+		})
+	}
+	wc.updates.handlers[updateName] = h
+	return nil
+}
+
+func (d *updateDispatcher) getOutcome(updateID string) (updateOutcome, error) {
+	if outcome, ok := d.outcomes[updateID]; ok {
+		return *outcome, nil
+	}
+	return updateOutcome{Unknown: true}, nil
+}
+
+// dispatch validates the request and starts the handler in a new coroutine. Requests are deduplicated by ID,
+// so the signal can be retried by the caller. Only the outcomes of the last maxCompletedUpdateOutcomes completed
+// updates are kept, a retry of an older update is applied again.
+func (d *updateDispatcher) dispatch(request updateRequest, dc DataConverter) {
+	if _, ok := d.outcomes[request.ID]; ok {
+		return
+	}
+	outcome := &updateOutcome{}
+	d.outcomes[request.ID] = outcome
+	defer func() {
+		if outcome.Completed {
+			d.complete(request.ID)
+		}
+	}()
+
+	h, ok := d.handlers[request.Name]
+	if !ok {
+		var known []string
+		for name := range d.handlers {
+			known = append(known, name)
+		}
+		sort.Strings(known)
+		outcome.reject(fmt.Errorf("unknown update %v. KnownUpdates=%v", request.Name, known), dc)
+		return
+	}
+
+	args, err := decodeArgs(dc, reflect.TypeOf(h.fn), request.Args)
+	if err != nil {
+		outcome.reject(fmt.Errorf("unable to decode the input for update %v: %v", request.Name, err), dc)
+		return
+	}
+	ctxValue := reflect.ValueOf(h.ctx)
+	if h.validator != nil {
+		ret := reflect.ValueOf(h.validator).Call(append([]reflect.Value{ctxValue}, args...))
+		if err, _ := ret[0].Interface().(error); err != nil {
+			outcome.reject(err, dc)
+			return
+		}
+	}
+
+	GoNamed(h.ctx, "update-"+request.Name, func(ctx Context) {
+		ret := reflect.ValueOf(h.fn).Call(append([]reflect.Value{reflect.ValueOf(ctx)}, args...))
+		if err, _ := ret[len(ret)-1].Interface().(error); err != nil {
+			outcome.Reason, outcome.Details = getErrorDetails(err, dc)
+		} else if len(ret) == 2 {
+			result := ret[0]
+			if result.Kind() != reflect.Ptr || !result.IsNil() {
+				if outcome.Result, err = encodeArg(dc, result.Interface()); err != nil {
+					outcome.Reason, outcome.Details = getErrorDetails(err, dc)
+				}
+			}
+		}
+		outcome.Completed = true
+		d.complete(request.ID)
+	})
+}
+
+// complete records the completion of the update and evicts the oldest completed outcomes. The eviction only
+// depends on the order updates complete in, so it is the same on replay.
+func (d *updateDispatcher) complete(updateID string) {
+	d.completed = append(d.completed, updateID)
+	if len(d.completed) > maxCompletedUpdateOutcomes {
+		delete(d.outcomes, d.completed[0])
+		d.completed = d.completed[1:]
+	}
+}
+
+func (o *updateOutcome) reject(err error, dc DataConverter) {
+	o.Completed = true
+	o.Rejected = true
+	o.Reason, o.Details = getErrorDetails(err, dc)
+}
+
+// error returns the error the update completed with, nil if it succeeded
+func (o *updateOutcome) error(dc DataConverter) error {
+	if o.Reason == "" {
+		return nil
+	}
+	err := constructError(o.Reason, o.Details, dc)
+	if o.Rejected {
+		return &UpdateRejectedError{cause: err}
+	}
+	return err
+}
+
+func (h *updateHandler) validateHandlerFn() error {
+	fnType := reflect.TypeOf(h.fn)
+	if fnType == nil || fnType.Kind() != reflect.Func {
+		return fmt.Errorf("update handler must be function but was %v", fnType)
+	}
+	if err := validateFnFormat(fnType, true); err != nil {
+		return fmt.Errorf("invalid update handler: %v", err)
+	}
+	if h.validator == nil {
+		return nil
+	}
+	validatorType := reflect.TypeOf(h.validator)
+	if validatorType.Kind() != reflect.Func || validatorType.NumOut() != 1 || !isError(validatorType.Out(0)) {
+		return errors.New("update validator must be function returning error")
+	}
+	if validatorType.NumIn() != fnType.NumIn() {
+		return errors.New("update validator must accept the same parameters as the update handler")
+	}
+	for i := 0; i < fnType.NumIn(); i++ {
+		if validatorType.In(i) != fnType.In(i) {
+			return errors.New("update validator must accept the same parameters as the update handler")
+		}
+	}
+	return nil
+}
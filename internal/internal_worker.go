@@ -188,6 +188,8 @@ type (
 		Tracer opentracing.Tracer
 
 		WorkflowInterceptors []WorkflowInterceptorFactory
+
+		ActivityInterceptors []ActivityInterceptorFactory
 	}
 )
 
@@ -672,30 +674,29 @@ func (we *workflowExecutor) Execute(ctx Context, input []byte) ([]byte, error) {
 
 // Wrapper to execute activity functions.
 type activityExecutor struct {
-	name string
-	fn   interface{}
+	name          string
+	fn            interface{}
+	autoHeartbeat bool
 }
 
 func (ae *activityExecutor) ActivityType() ActivityType {
 	return ActivityType{Name: ae.name}
 }
 
+func (ae *activityExecutor) isAutoHeartbeatEnabled() bool {
+	return ae.autoHeartbeat
+}
+
 func (ae *activityExecutor) GetFunction() interface{} {
 	return ae.fn
 }
 
 func (ae *activityExecutor) Execute(ctx context.Context, input []byte) ([]byte, error) {
-	fnType := reflect.TypeOf(ae.fn)
-	var args []reflect.Value
+	var args []interface{}
 	dataConverter := getDataConverterFromActivityCtx(ctx)
-
-	// activities optionally might not take context.
-	if fnType.NumIn() > 0 && isActivityContext(fnType.In(0)) {
-		args = append(args, reflect.ValueOf(ctx))
-	}
-
+	fnType := reflect.TypeOf(ae.fn)
 	if fnType.NumIn() == 1 && util.IsTypeByteSlice(fnType.In(0)) {
-		args = append(args, reflect.ValueOf(input))
+		args = append(args, input)
 	} else {
 		decoded, err := decodeArgs(dataConverter, fnType, input)
 		if err != nil {
@@ -703,19 +704,28 @@ func (ae *activityExecutor) Execute(ctx context.Context, input []byte) ([]byte,
 				"unable to decode the activity function input bytes with error: %v for function name: %v",
 				err, ae.name)
 		}
-		args = append(args, decoded...)
+		for _, arg := range decoded {
+			args = append(args, arg.Interface())
+		}
 	}
-
-	fnValue := reflect.ValueOf(ae.fn)
-	retValues := fnValue.Call(args)
-	return validateFunctionAndGetResults(ae.fn, retValues, dataConverter)
+	return ae.ExecuteWithActualArgs(ctx, args)
 }
 
 func (ae *activityExecutor) ExecuteWithActualArgs(ctx context.Context, actualArgs []interface{}) ([]byte, error) {
-	retValues := ae.executeWithActualArgsWithoutParseResult(ctx, actualArgs)
 	dataConverter := getDataConverterFromActivityCtx(ctx)
-
-	return validateFunctionAndGetResults(ae.fn, retValues, dataConverter)
+	ctx, interceptor := newActivityInterceptors(ctx, ae.fn)
+	if ae.autoHeartbeat {
+		stopAutoHeartbeat := startAutoHeartbeat(ctx, interceptor)
+		defer stopAutoHeartbeat()
+	}
+	results := interceptor.ExecuteActivity(ctx, ae.name, actualArgs...)
+	if len(results) > 1 {
+		// nil pointer result is reported as no result
+		if v := reflect.ValueOf(results[0]); v.Kind() == reflect.Ptr && v.IsNil() {
+			results[0] = nil
+		}
+	}
+	return serializeResults(ae.fn, results, dataConverter)
 }
 
 func (ae *activityExecutor) executeWithActualArgsWithoutParseResult(ctx context.Context, actualArgs []interface{}) []reflect.Value {
@@ -778,6 +788,14 @@ func (aw *aggregatedWorker) RegisterActivityWithOptions(a interface{}, options R
 	aw.registry.RegisterActivityWithOptions(a, options)
 }
 
+func (aw *aggregatedWorker) GetRegisteredWorkflows() []RegisteredWorkflowInfo {
+	return aw.registry.listWorkflows()
+}
+
+func (aw *aggregatedWorker) GetRegisteredActivities() []RegisteredActivityInfo {
+	return aw.registry.listActivities()
+}
+
 func (aw *aggregatedWorker) Start() error {
 	if err := initBinaryChecksum(); err != nil {
 		return fmt.Errorf("failed to get executable checksum: %v", err)
@@ -951,6 +969,7 @@ func newAggregatedWorker(
 		ContextPropagators:                   wOptions.ContextPropagators,
 		Tracer:                               wOptions.Tracer,
 		WorkflowInterceptors:                 wOptions.WorkflowInterceptorChainFactories,
+		ActivityInterceptors:                 wOptions.ActivityInterceptorChainFactories,
 	}
 
 	ensureRequiredParams(&workerParams)
@@ -966,7 +985,7 @@ func newAggregatedWorker(
 	processTestTags(&wOptions, &workerParams)
 
 	// worker specific registry
-	registry := newRegistry()
+	registry := newRegistryWithOptions(registryOptions{disableGlobalRegistry: wOptions.DisableGlobalRegistry})
 
 	// workflow factory.
 	var workflowWorker *workflowWorker
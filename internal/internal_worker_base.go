@@ -100,6 +100,8 @@ type (
 		// Executed after all history events since the previous decision are applied to workflowDefinition
 		OnDecisionTaskStarted()
 		StackTrace() string // Stack trace of all coroutines owned by the Dispatcher instance
+		// Sorted names of the query and signal handlers registered by the workflow code
+		HandlerNames() (queryTypes []string, signalNames []string)
 		Close()
 	}
 
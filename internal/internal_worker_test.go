@@ -962,6 +962,62 @@ func TestRegisterVariousWorkflowTypes(t *testing.T) {
 	r.RegisterWorkflow(testWorkflowReturnStructPtrPtr)
 }
 
+func testGlobalRegistryWorkflow(ctx Context) error {
+	return nil
+}
+
+func testGlobalRegistryActivity(ctx context.Context) error {
+	return nil
+}
+
+func TestWorkerDisableGlobalRegistry(t *testing.T) {
+	// the functions registered globally are not used by other tests, as registering them sets global aliases
+	getGlobalRegistry().RegisterWorkflowWithOptions(testGlobalRegistryWorkflow, RegisterWorkflowOptions{
+		Name:                          "isolatedRegistryWorkflow",
+		DisableAlreadyRegisteredCheck: true,
+	})
+	getGlobalRegistry().RegisterActivityWithOptions(testGlobalRegistryActivity, RegisterActivityOptions{
+		Name:                          "isolatedRegistryActivity",
+		DisableAlreadyRegisteredCheck: true,
+	})
+
+	mockCtrl := gomock.NewController(t)
+	service := workflowservicetest.NewMockClient(mockCtrl)
+
+	isolated := NewWorker(service, "testDomain", "isolatedTaskList", WorkerOptions{
+		Logger:                zap.NewNop(),
+		DisableGlobalRegistry: true,
+	})
+	isolated.RegisterWorkflowWithOptions(testWorkflowReturnInt, RegisterWorkflowOptions{Name: "isolatedRegistryWorkflow"})
+	isolated.RegisterActivity(testActivityReturnString)
+
+	workflows := isolated.GetRegisteredWorkflows()
+	require.Len(t, workflows, 1)
+	require.Equal(t, "isolatedRegistryWorkflow", workflows[0].WorkflowType.Name)
+	require.Equal(t, getFunctionName(testWorkflowReturnInt), getFunctionName(workflows[0].Fn))
+	activities := isolated.GetRegisteredActivities()
+	require.Len(t, activities, 1)
+	require.Equal(t, getFunctionName(testActivityReturnString), activities[0].ActivityType.Name)
+
+	shared := NewWorker(service, "testDomain", "sharedTaskList", WorkerOptions{Logger: zap.NewNop()})
+	shared.RegisterWorkflowWithOptions(testWorkflowReturnInt, RegisterWorkflowOptions{Name: "isolatedRegistryWorkflow"})
+
+	var found bool
+	for _, w := range shared.GetRegisteredWorkflows() {
+		if w.WorkflowType.Name == "isolatedRegistryWorkflow" {
+			require.False(t, found, "workflow registered with the worker shadows the global one")
+			require.Equal(t, getFunctionName(testWorkflowReturnInt), getFunctionName(w.Fn))
+			found = true
+		}
+	}
+	require.True(t, found)
+	var globalActivityFound bool
+	for _, a := range shared.GetRegisteredActivities() {
+		globalActivityFound = globalActivityFound || a.ActivityType.Name == "isolatedRegistryActivity"
+	}
+	require.True(t, globalActivityFound)
+}
+
 type testErrorDetails struct {
 	T string
 }
@@ -26,8 +26,10 @@ package internal
 import (
 	"errors"
 	"fmt"
+	"math/rand"
 	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -78,6 +80,24 @@ type (
 		settable Settable // used to unblock the future when all coroutines have completed
 	}
 
+	// Implements Semaphore interface
+	semaphoreImpl struct {
+		name    string
+		size    int64
+		cur     int64              // the sum of acquired weights
+		waiters []*semaphoreWaiter // coroutines blocked in acquire in arrival order
+	}
+
+	semaphoreWaiter struct {
+		n     int64
+		ready bool // set when the weight is granted to the waiter
+	}
+
+	// Implements Mutex interface
+	mutexImpl struct {
+		semaphore *semaphoreImpl
+	}
+
 	// Dispatcher is a container of a set of coroutines.
 	dispatcher interface {
 		// ExecuteUntilAllBlocked executes coroutines one by one in deterministic order
@@ -155,13 +175,15 @@ type (
 	}
 
 	dispatcherImpl struct {
-		sequence         int
-		channelSequence  int // used to name channels
-		selectorSequence int // used to name channels
-		coroutines       []*coroutineState
-		executing        bool       // currently running ExecuteUntilAllBlocked. Used to avoid recursive calls to it.
-		mutex            sync.Mutex // used to synchronize executing
-		closed           bool
+		sequence          int
+		channelSequence   int // used to name channels
+		selectorSequence  int // used to name channels
+		mutexSequence     int // used to name mutexes
+		semaphoreSequence int // used to name semaphores
+		coroutines        []*coroutineState
+		executing         bool       // currently running ExecuteUntilAllBlocked. Used to avoid recursive calls to it.
+		mutex             sync.Mutex // used to synchronize executing
+		closed            bool
 	}
 
 	// The current timeout resolution implementation is in seconds and uses math.Ceil() as the duration. But is
@@ -173,6 +195,7 @@ type (
 		domain                              *string
 		workflowID                          string
 		waitForCancellation                 bool
+		cancellationType                    CancellationType
 		signalChannels                      map[string]Channel
 		queryHandlers                       map[string]func([]byte) ([]byte, error)
 		workflowIDReusePolicy               WorkflowIDReusePolicy
@@ -247,6 +270,8 @@ const (
 var _ Channel = (*channelImpl)(nil)
 var _ Selector = (*selectorImpl)(nil)
 var _ WaitGroup = (*waitGroupImpl)(nil)
+var _ Semaphore = (*semaphoreImpl)(nil)
+var _ Mutex = (*mutexImpl)(nil)
 var _ dispatcher = (*dispatcherImpl)(nil)
 
 var stackBuf [100000]byte
@@ -280,6 +305,12 @@ type workflowEnvironmentInterceptor struct {
 	env                  workflowEnvironment
 	interceptorChainHead WorkflowInterceptor
 	fn                   interface{}
+	updates              *updateDispatcher
+	signals              *signalDispatcher
+	random               *rand.Rand
+	// names of the signal channels requested by workflow code, channels created for signals that nobody
+	// listens to are not included
+	signalListeners map[string]bool
 }
 
 func getWorkflowInterceptor(ctx Context) WorkflowInterceptor {
@@ -483,6 +514,9 @@ func (d *syncWorkflowDefinition) Execute(env workflowEnvironment, header *shared
 	})
 
 	getWorkflowEnvironment(d.rootCtx).RegisterSignalHandler(func(name string, result []byte) {
+		if envInterceptor.signals.accept(name, result) {
+			return
+		}
 		eo := getWorkflowEnvOptions(d.rootCtx)
 		// We don't want this code to be blocked ever, using sendAsync().
 		ch := eo.getSignalChannel(d.rootCtx, name).(*channelImpl)
@@ -496,7 +530,7 @@ func (d *syncWorkflowDefinition) Execute(env workflowEnvironment, header *shared
 		eo := getWorkflowEnvOptions(d.rootCtx)
 		handler, ok := eo.queryHandlers[queryType]
 		if !ok {
-			keys := []string{QueryTypeStackTrace, QueryTypeOpenSessions}
+			keys := []string{QueryTypeStackTrace, QueryTypeOpenSessions, QueryTypePendingOperations}
 			for k := range eo.queryHandlers {
 				keys = append(keys, k)
 			}
@@ -514,6 +548,31 @@ func (d *syncWorkflowDefinition) StackTrace() string {
 	return d.dispatcher.StackTrace()
 }
 
+func (d *syncWorkflowDefinition) HandlerNames() (queryTypes []string, signalNames []string) {
+	eo := getWorkflowEnvOptions(d.rootCtx)
+	for queryType := range eo.queryHandlers {
+		if queryType != updateQueryType {
+			queryTypes = append(queryTypes, queryType)
+		}
+	}
+	wc := getEnvInterceptor(d.rootCtx)
+	for signalName := range wc.signalListeners {
+		if signalName != updateSignalName {
+			signalNames = append(signalNames, signalName)
+		}
+	}
+	if wc.signals != nil {
+		for signalName := range wc.signals.handlers {
+			if !wc.signalListeners[signalName] {
+				signalNames = append(signalNames, signalName)
+			}
+		}
+	}
+	sort.Strings(queryTypes)
+	sort.Strings(signalNames)
+	return queryTypes, signalNames
+}
+
 func (d *syncWorkflowDefinition) Close() {
 	if d.dispatcher != nil {
 		d.dispatcher.Close()
@@ -545,7 +604,7 @@ func executeDispatcher(ctx Context, dispatcher dispatcher) {
 		return
 	}
 
-	us := getWorkflowEnvOptions(ctx).getUnhandledSignals()
+	us := getEnvInterceptor(ctx).GetUnhandledSignalNames(ctx)
 	if len(us) > 0 {
 		env.GetLogger().Info("Workflow has unhandled signals", zap.Strings("SignalNames", us))
 		env.GetMetricsScope().Counter(metrics.UnhandledSignalsCounter).Inc(1)
@@ -1440,3 +1499,110 @@ func (wg *waitGroupImpl) Wait(ctx Context) {
 	}
 	wg.future, wg.settable = NewFuture(ctx)
 }
+
+// Acquire blocks until the weight of n is acquired or the ctx is canceled.
+// Waiters are granted the weight in the order they called Acquire.
+func (s *semaphoreImpl) Acquire(ctx Context, n int64) error {
+	return s.acquire(ctx, n, "Acquire")
+}
+
+func (s *semaphoreImpl) acquire(ctx Context, n int64, op string) error {
+	if n <= 0 {
+		return fmt.Errorf("%s: weight %v must be positive", s.name, n)
+	}
+	if n > s.size {
+		return fmt.Errorf("%s: weight %v exceeds the semaphore size %v", s.name, n, s.size)
+	}
+	if s.TryAcquire(ctx, n) {
+		return nil
+	}
+
+	w := &semaphoreWaiter{n: n}
+	s.waiters = append(s.waiters, w)
+	state := getState(ctx)
+	defer state.unblocked()
+	for !w.ready {
+		doneCh := ctx.Done()
+		if doneCh != nil {
+			if _, more := doneCh.ReceiveAsyncWithMoreFlag(nil); !more {
+				s.removeWaiter(w)
+				return NewCanceledError(fmt.Sprintf("%s.%s context cancelled", s.name, op))
+			}
+		}
+		state.yield(fmt.Sprintf("blocked on %s.%s", s.name, op))
+	}
+	return nil
+}
+
+// TryAcquire acquires the weight of n without blocking. It returns false and leaves the semaphore unchanged
+// if the weight is not available or other coroutines are already waiting.
+func (s *semaphoreImpl) TryAcquire(ctx Context, n int64) bool {
+	if n <= 0 {
+		panic(fmt.Sprintf("%s: weight %v must be positive", s.name, n))
+	}
+	if len(s.waiters) == 0 && s.size-s.cur >= n {
+		s.cur += n
+		return true
+	}
+	return false
+}
+
+// Release releases the weight of n and unblocks the waiters the released weight can be granted to.
+func (s *semaphoreImpl) Release(n int64) {
+	if n <= 0 {
+		panic(fmt.Sprintf("%s: weight %v must be positive", s.name, n))
+	}
+	if n > s.cur {
+		panic(fmt.Sprintf("%s: released more than held", s.name))
+	}
+	s.cur -= n
+	s.notifyWaiters()
+}
+
+func (s *semaphoreImpl) notifyWaiters() {
+	for len(s.waiters) > 0 {
+		w := s.waiters[0]
+		if s.size-s.cur < w.n {
+			// keep FIFO order to not starve waiters of a large weight
+			return
+		}
+		s.cur += w.n
+		w.ready = true
+		s.waiters[0] = nil
+		s.waiters = s.waiters[1:]
+	}
+}
+
+func (s *semaphoreImpl) removeWaiter(w *semaphoreWaiter) {
+	for i, waiter := range s.waiters {
+		if waiter == w {
+			s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+			break
+		}
+	}
+	// the removed waiter could have been blocking the ones behind it
+	s.notifyWaiters()
+}
+
+// Lock blocks until the mutex is acquired or the ctx is canceled.
+func (m *mutexImpl) Lock(ctx Context) error {
+	return m.semaphore.acquire(ctx, 1, "Lock")
+}
+
+// TryLock acquires the mutex if it is not locked and returns whether it succeeded.
+func (m *mutexImpl) TryLock(ctx Context) bool {
+	return m.semaphore.TryAcquire(ctx, 1)
+}
+
+// Unlock releases the mutex and unblocks the first coroutine waiting in Lock.
+func (m *mutexImpl) Unlock() {
+	if m.semaphore.cur == 0 {
+		panic(fmt.Sprintf("%s: unlock of unlocked mutex", m.semaphore.name))
+	}
+	m.semaphore.Release(1)
+}
+
+// IsLocked returns whether the mutex is held.
+func (m *mutexImpl) IsLocked() bool {
+	return m.semaphore.cur > 0
+}
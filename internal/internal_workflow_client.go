@@ -48,6 +48,7 @@ var _ DomainClient = (*domainClient)(nil)
 const (
 	defaultDecisionTaskTimeoutInSecs = 10
 	defaultGetHistoryTimeoutInSecs   = 25
+	defaultUpdatePollInterval        = 200 * time.Millisecond
 )
 
 var (
@@ -65,6 +66,9 @@ type (
 		dataConverter      DataConverter
 		contextPropagators []ContextPropagator
 		tracer             opentracing.Tracer
+		// interceptedClient is the head of the interceptor chain, calls the client makes to its own
+		// methods go through it so that interceptors observe them.
+		interceptedClient Client
 	}
 
 	// domainClient is the client for managing domains.
@@ -263,7 +267,7 @@ func (wc *workflowClient) ExecuteWorkflow(ctx context.Context, options StartWork
 	// start the workflow execution
 	var runID string
 	var workflowID string
-	executionInfo, err := wc.StartWorkflow(ctx, options, workflow, args...)
+	executionInfo, err := wc.intercepted().StartWorkflow(ctx, options, workflow, args...)
 	if err != nil {
 		if alreadyStartedErr, ok := err.(*s.WorkflowExecutionAlreadyStartedError); ok {
 			runID = alreadyStartedErr.GetRunId()
@@ -279,7 +283,7 @@ func (wc *workflowClient) ExecuteWorkflow(ctx context.Context, options StartWork
 	}
 
 	iterFn := func(fnCtx context.Context, fnRunID string) HistoryEventIterator {
-		return wc.GetWorkflowHistory(fnCtx, workflowID, fnRunID, true, s.HistoryEventFilterTypeCloseEvent)
+		return wc.intercepted().GetWorkflowHistory(fnCtx, workflowID, fnRunID, true, s.HistoryEventFilterTypeCloseEvent)
 	}
 
 	return &workflowRunImpl{
@@ -300,7 +304,7 @@ func (wc *workflowClient) ExecuteWorkflow(ctx context.Context, options StartWork
 func (wc *workflowClient) GetWorkflow(ctx context.Context, workflowID string, runID string) WorkflowRun {
 
 	iterFn := func(fnCtx context.Context, fnRunID string) HistoryEventIterator {
-		return wc.GetWorkflowHistory(fnCtx, workflowID, fnRunID, true, s.HistoryEventFilterTypeCloseEvent)
+		return wc.intercepted().GetWorkflowHistory(fnCtx, workflowID, fnRunID, true, s.HistoryEventFilterTypeCloseEvent)
 	}
 
 	return &workflowRunImpl{
@@ -837,7 +841,7 @@ func (wc *workflowClient) QueryWorkflow(ctx context.Context, workflowID string,
 		QueryType:  queryType,
 		Args:       args,
 	}
-	result, err := wc.QueryWorkflowWithOptions(ctx, queryWorkflowWithOptionsRequest)
+	result, err := wc.intercepted().QueryWorkflowWithOptions(ctx, queryWorkflowWithOptionsRequest)
 	if err != nil {
 		return nil, err
 	}
@@ -937,6 +941,132 @@ func (wc *workflowClient) QueryWorkflowWithOptions(ctx context.Context, request
 	}, nil
 }
 
+// UpdateWorkflow sends an update to a workflow execution and waits for its result.
+// See UpdateWorkflowWithOptions for more information.
+func (wc *workflowClient) UpdateWorkflow(ctx context.Context, workflowID string, runID string, updateName string, args ...interface{}) (Value, error) {
+	return wc.intercepted().UpdateWorkflowWithOptions(ctx, &UpdateWorkflowWithOptionsRequest{
+		WorkflowID: workflowID,
+		RunID:      runID,
+		UpdateName: updateName,
+		Args:       args,
+	})
+}
+
+// UpdateWorkflowWithOptionsRequest is the request to UpdateWorkflowWithOptions
+type UpdateWorkflowWithOptionsRequest struct {
+	// UpdateID is an optional field identifying the update. The workflow handles an update ID only once,
+	// so retrying a request with the same UpdateID does not apply the update twice.
+	// If UpdateID is not provided a random one will be used.
+	UpdateID string
+
+	// WorkflowID is a required field indicating the workflow which should be updated.
+	WorkflowID string
+
+	// RunID is an optional field used to identify a specific run of the updated workflow.
+	// If RunID is not provided the current run is looked up before sending the update, so the outcome is
+	// polled from the same run even if the workflow continues as new meanwhile.
+	RunID string
+
+	// UpdateName is a required field which specifies the update handler to invoke.
+	// See comments at workflow.SetUpdateHandler for more details on how to setup update handler within the target workflow.
+	UpdateName string
+
+	// Args is an optional field used to identify the arguments passed to the update handler.
+	Args []interface{}
+
+	// PollInterval is an optional field controlling how often the outcome of the update is polled.
+	// Default: 200ms
+	PollInterval time.Duration
+}
+
+// UpdateWorkflowWithOptions sends an update to a workflow execution and blocks until the update handler completes,
+// the workflow rejects the update or the context is done. The update is delivered as a signal and its outcome is
+// polled with a query, so the target workflow must be processed by workers able to answer queries. While the
+// workflow does not know the update the signal is resent, and an error is returned if the outcome of the update is
+// no longer kept by the workflow.
+// The errors it can return:
+//  - UpdateRejectedError
+//  - the error returned by the update handler
+//  - BadRequestError
+//  - InternalServiceError
+//  - EntityNotExistError
+//  - QueryFailError
+func (wc *workflowClient) UpdateWorkflowWithOptions(ctx context.Context, request *UpdateWorkflowWithOptionsRequest) (Value, error) {
+	if request.UpdateName == "" {
+		return nil, errors.New("updateName is required")
+	}
+	updateID := request.UpdateID
+	if updateID == "" {
+		updateID = uuid.New()
+	}
+	pollInterval := request.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultUpdatePollInterval
+	}
+
+	input, err := encodeArgs(wc.dataConverter, request.Args)
+	if err != nil {
+		return nil, err
+	}
+	runID := request.RunID
+	if runID == "" {
+		// pin the current run, so that the outcome is not polled from the next run after a continue as new
+		resp, err := wc.intercepted().DescribeWorkflowExecution(ctx, request.WorkflowID, "")
+		if err != nil {
+			return nil, err
+		}
+		if info := resp.WorkflowExecutionInfo; info != nil && info.Execution != nil {
+			runID = info.Execution.GetRunId()
+		}
+	}
+	signal := func() error {
+		return wc.intercepted().SignalWorkflow(ctx, request.WorkflowID, runID, updateSignalName, updateRequest{
+			ID:   updateID,
+			Name: request.UpdateName,
+			Args: input,
+		})
+	}
+	if err := signal(); err != nil {
+		return nil, err
+	}
+
+	// known is set once the workflow reported the update, so that an eviction of its outcome is not mistaken for
+	// a signal the workflow has not processed yet
+	known := false
+	for {
+		result, err := wc.intercepted().QueryWorkflow(ctx, request.WorkflowID, runID, updateQueryType, updateID)
+		if err != nil {
+			return nil, err
+		}
+		var outcome updateOutcome
+		if err := result.Get(&outcome); err != nil {
+			return nil, err
+		}
+		switch {
+		case outcome.Unknown && known:
+			return nil, fmt.Errorf("outcome of update %v is no longer kept by the workflow", updateID)
+		case outcome.Unknown:
+			// the signal is deduplicated by the update ID, resending it only applies the update if it was lost
+			if err := signal(); err != nil {
+				return nil, err
+			}
+		case outcome.Completed:
+			if err := outcome.error(wc.dataConverter); err != nil {
+				return nil, err
+			}
+			return newEncodedValue(outcome.Result, wc.dataConverter), nil
+		default:
+			known = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
 // DescribeTaskList returns information about the target tasklist, right now this API returns the
 // pollers which polled this tasklist in last few minutes.
 // - tasklist name of tasklist
@@ -1036,6 +1166,14 @@ func (dc *domainClient) Update(ctx context.Context, request *s.UpdateDomainReque
 		}, createDynamicServiceRetryPolicy(ctx), isServiceTransientError)
 }
 
+// intercepted returns the head of the interceptor chain, or wc itself if it was not created by NewClient.
+func (wc *workflowClient) intercepted() Client {
+	if wc.interceptedClient != nil {
+		return wc.interceptedClient
+	}
+	return wc
+}
+
 func getRunID(runID string) *string {
 	if runID == "" {
 		// Cadence Server will pick current runID if provided empty.
@@ -1145,6 +1145,58 @@ func (s *workflowClientTestSuite) TestStartWorkflow_WithMemoAndSearchAttr() {
 	s.client.StartWorkflow(context.Background(), options, wf)
 }
 
+func (s *workflowClientTestSuite) TestStartWorkflow_WithInterceptors() {
+	interceptor := &testClientInterceptorFactory{}
+	client := NewClient(s.service, domain, &ClientOptions{Interceptors: []ClientInterceptorFactory{interceptor}})
+	options := StartWorkflowOptions{
+		ID:                              workflowID,
+		TaskList:                        tasklist,
+		ExecutionStartToCloseTimeout:    timeoutInSeconds,
+		DecisionTaskStartToCloseTimeout: timeoutInSeconds,
+	}
+	wf := func(ctx Context) string {
+		return "result"
+	}
+	startResp := &shared.StartWorkflowExecutionResponse{RunId: common.StringPtr(runID)}
+
+	s.service.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(startResp, nil).
+		Do(func(_ interface{}, req *shared.StartWorkflowExecutionRequest, _ ...interface{}) {
+			s.Equal("tenant-"+workflowID, req.GetWorkflowId())
+		})
+	resp, err := client.StartWorkflow(context.Background(), options, wf)
+	s.NoError(err)
+	s.Equal(runID, resp.RunID)
+
+	err = client.SignalWorkflow(context.Background(), workflowID, runID, "forbidden", nil)
+	s.EqualError(err, "signal forbidden is not allowed")
+
+	s.Equal([]string{
+		"StartWorkflow tenant-" + workflowID + " " + runID,
+		"SignalWorkflow forbidden",
+	}, interceptor.instance.trace)
+}
+
+func (s *workflowClientTestSuite) TestExecuteWorkflow_WithInterceptors() {
+	interceptor := &testClientInterceptorFactory{}
+	client := NewClient(s.service, domain, &ClientOptions{Interceptors: []ClientInterceptorFactory{interceptor}})
+	options := StartWorkflowOptions{
+		ID:                              workflowID,
+		TaskList:                        tasklist,
+		ExecutionStartToCloseTimeout:    timeoutInSeconds,
+		DecisionTaskStartToCloseTimeout: timeoutInSeconds,
+	}
+	startResp := &shared.StartWorkflowExecutionResponse{RunId: common.StringPtr(runID)}
+	s.service.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(startResp, nil).
+		Do(func(_ interface{}, req *shared.StartWorkflowExecutionRequest, _ ...interface{}) {
+			s.Equal("tenant-"+workflowID, req.GetWorkflowId())
+		})
+
+	run, err := client.ExecuteWorkflow(context.Background(), options, "workflowType")
+	s.NoError(err)
+	s.Equal("tenant-"+workflowID, run.GetID())
+	s.Equal([]string{"StartWorkflow tenant-" + workflowID + " " + runID}, interceptor.instance.trace)
+}
+
 func (s *workflowClientTestSuite) SignalWithStartWorkflowWithMemoAndSearchAttr() {
 	memo := map[string]interface{}{
 		"testMemo": "memo value",
@@ -1331,6 +1383,430 @@ func (s *workflowClientTestSuite) TestGetSearchAttributes() {
 	s.Equal(responseErr, err)
 }
 
+func (s *workflowClientTestSuite) TestUpdateWorkflow() {
+	dc := getDefaultDataConverter()
+	s.service.EXPECT().DescribeWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&shared.DescribeWorkflowExecutionResponse{
+			WorkflowExecutionInfo: &shared.WorkflowExecutionInfo{
+				Execution: &shared.WorkflowExecution{WorkflowId: common.StringPtr(workflowID), RunId: common.StringPtr(runID)},
+			},
+		}, nil)
+	var signalRequest *shared.SignalWorkflowExecutionRequest
+	// the signal is resent while the workflow does not know the update
+	s.service.EXPECT().SignalWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ interface{}, req *shared.SignalWorkflowExecutionRequest, _ ...interface{}) {
+			signalRequest = req
+		}).Return(nil).Times(2)
+
+	unknown, _ := encodeArg(dc, updateOutcome{Unknown: true})
+	pending, _ := encodeArg(dc, updateOutcome{})
+	result, _ := encodeArg(dc, 42)
+	completed, _ := encodeArg(dc, updateOutcome{Completed: true, Result: result})
+	s.service.EXPECT().QueryWorkflow(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&shared.QueryWorkflowResponse{QueryResult: unknown}, nil)
+	s.service.EXPECT().QueryWorkflow(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&shared.QueryWorkflowResponse{QueryResult: pending}, nil)
+	s.service.EXPECT().QueryWorkflow(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ interface{}, req *shared.QueryWorkflowRequest, _ ...interface{}) {
+			s.Equal(runID, req.Execution.GetRunId())
+			s.Equal(updateQueryType, req.Query.GetQueryType())
+			var updateID string
+			s.NoError(dc.FromData(req.Query.QueryArgs, &updateID))
+			s.Equal("update-id", updateID)
+		}).Return(&shared.QueryWorkflowResponse{QueryResult: completed}, nil)
+
+	value, err := s.client.UpdateWorkflowWithOptions(context.Background(), &UpdateWorkflowWithOptionsRequest{
+		UpdateID:     "update-id",
+		WorkflowID:   workflowID,
+		UpdateName:   "add",
+		Args:         []interface{}{1},
+		PollInterval: time.Millisecond,
+	})
+	s.NoError(err)
+	var answer int
+	s.NoError(value.Get(&answer))
+	s.Equal(42, answer)
+
+	s.Equal(runID, signalRequest.WorkflowExecution.GetRunId())
+	s.Equal(updateSignalName, signalRequest.GetSignalName())
+	var request updateRequest
+	s.NoError(dc.FromData(signalRequest.Input, &request))
+	s.Equal("update-id", request.ID)
+	s.Equal("add", request.Name)
+}
+
+func (s *workflowClientTestSuite) TestUpdateWorkflow_Rejected() {
+	dc := getDefaultDataConverter()
+	s.service.EXPECT().SignalWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	reason, details := getErrorDetails(errors.New("negative value"), dc)
+	rejected, _ := encodeArg(dc, updateOutcome{Completed: true, Rejected: true, Reason: reason, Details: details})
+	s.service.EXPECT().QueryWorkflow(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&shared.QueryWorkflowResponse{QueryResult: rejected}, nil)
+
+	_, err := s.client.UpdateWorkflow(context.Background(), workflowID, runID, "add", -1)
+	s.IsType(&UpdateRejectedError{}, err)
+	s.Contains(err.Error(), "negative value")
+}
+
+func (s *workflowClientTestSuite) TestUpdateWorkflow_OutcomeEvicted() {
+	dc := getDefaultDataConverter()
+	s.service.EXPECT().SignalWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	pending, _ := encodeArg(dc, updateOutcome{})
+	unknown, _ := encodeArg(dc, updateOutcome{Unknown: true})
+	s.service.EXPECT().QueryWorkflow(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&shared.QueryWorkflowResponse{QueryResult: pending}, nil)
+	s.service.EXPECT().QueryWorkflow(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&shared.QueryWorkflowResponse{QueryResult: unknown}, nil)
+
+	_, err := s.client.UpdateWorkflowWithOptions(context.Background(), &UpdateWorkflowWithOptionsRequest{
+		UpdateID:     "update-id",
+		WorkflowID:   workflowID,
+		RunID:        runID,
+		UpdateName:   "add",
+		PollInterval: time.Millisecond,
+	})
+	s.Error(err)
+	s.Contains(err.Error(), "no longer kept")
+}
+
+func (s *workflowClientTestSuite) TestResetWorkflow_LastDecisionCompleted() {
+	s.service.EXPECT().DescribeWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&shared.DescribeWorkflowExecutionResponse{
+			WorkflowExecutionInfo: &shared.WorkflowExecutionInfo{
+				Execution: &shared.WorkflowExecution{WorkflowId: common.StringPtr(workflowID), RunId: common.StringPtr(runID)},
+			},
+		}, nil)
+	s.service.EXPECT().GetWorkflowExecutionHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ interface{}, req *shared.GetWorkflowExecutionHistoryRequest, _ ...interface{}) {
+			s.Equal(runID, req.Execution.GetRunId())
+		}).
+		Return(&shared.GetWorkflowExecutionHistoryResponse{
+			History: &shared.History{
+				Events: []*shared.HistoryEvent{
+					createTestEventWorkflowExecutionStarted(1, &shared.WorkflowExecutionStartedEventAttributes{}),
+					createTestEventDecisionTaskScheduled(2, &shared.DecisionTaskScheduledEventAttributes{}),
+					createTestEventDecisionTaskStarted(3),
+					createTestEventDecisionTaskCompleted(4, &shared.DecisionTaskCompletedEventAttributes{}),
+					createTestEventDecisionTaskScheduled(5, &shared.DecisionTaskScheduledEventAttributes{}),
+					createTestEventDecisionTaskStarted(6),
+					createTestEventDecisionTaskCompleted(7, &shared.DecisionTaskCompletedEventAttributes{}),
+				},
+			},
+		}, nil)
+	s.service.EXPECT().ResetWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ interface{}, req *shared.ResetWorkflowExecutionRequest, _ ...interface{}) {
+			s.Equal(domain, req.GetDomain())
+			s.Equal(workflowID, req.WorkflowExecution.GetWorkflowId())
+			s.Equal(runID, req.WorkflowExecution.GetRunId())
+			s.Equal(int64(7), req.GetDecisionFinishEventId())
+			s.Equal("stuck", req.GetReason())
+			s.NotEmpty(req.GetRequestId())
+		}).
+		Return(&shared.ResetWorkflowExecutionResponse{RunId: common.StringPtr("new run ID")}, nil)
+
+	execution, err := s.client.ResetWorkflow(context.Background(), &ResetWorkflowRequest{
+		WorkflowID: workflowID,
+		Reason:     "stuck",
+		ResetPoint: ResetPointLastDecisionCompleted(),
+	})
+	s.NoError(err)
+	s.Equal(&WorkflowExecution{ID: workflowID, RunID: "new run ID"}, execution)
+}
+
+func (s *workflowClientTestSuite) TestResetWorkflow_FirstDecisionCompleted() {
+	s.service.EXPECT().GetWorkflowExecutionHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&shared.GetWorkflowExecutionHistoryResponse{
+			History: &shared.History{
+				Events: []*shared.HistoryEvent{
+					createTestEventWorkflowExecutionStarted(1, &shared.WorkflowExecutionStartedEventAttributes{}),
+					createTestEventDecisionTaskCompleted(4, &shared.DecisionTaskCompletedEventAttributes{}),
+					createTestEventDecisionTaskCompleted(7, &shared.DecisionTaskCompletedEventAttributes{}),
+				},
+			},
+		}, nil)
+	s.service.EXPECT().ResetWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ interface{}, req *shared.ResetWorkflowExecutionRequest, _ ...interface{}) {
+			s.Equal(runID, req.WorkflowExecution.GetRunId())
+			s.Equal(int64(4), req.GetDecisionFinishEventId())
+			s.Equal("request-id", req.GetRequestId())
+		}).
+		Return(&shared.ResetWorkflowExecutionResponse{RunId: common.StringPtr("new run ID")}, nil)
+
+	_, err := s.client.ResetWorkflow(context.Background(), &ResetWorkflowRequest{
+		WorkflowID: workflowID,
+		RunID:      runID,
+		ResetPoint: ResetPointFirstDecisionCompleted(),
+		RequestID:  "request-id",
+	})
+	s.NoError(err)
+}
+
+func (s *workflowClientTestSuite) TestResetWorkflow_BadBinary() {
+	s.service.EXPECT().DescribeWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&shared.DescribeWorkflowExecutionResponse{
+			WorkflowExecutionInfo: &shared.WorkflowExecutionInfo{
+				AutoResetPoints: &shared.ResetPoints{
+					Points: []*shared.ResetPointInfo{
+						{BinaryChecksum: common.StringPtr("good"), RunId: common.StringPtr("first run ID"), FirstDecisionCompletedId: common.Int64Ptr(4), Resettable: common.BoolPtr(true)},
+						{BinaryChecksum: common.StringPtr("bad"), RunId: common.StringPtr("second run ID"), FirstDecisionCompletedId: common.Int64Ptr(12), Resettable: common.BoolPtr(true)},
+					},
+				},
+			},
+		}, nil)
+	s.service.EXPECT().ResetWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ interface{}, req *shared.ResetWorkflowExecutionRequest, _ ...interface{}) {
+			s.Equal("second run ID", req.WorkflowExecution.GetRunId())
+			s.Equal(int64(12), req.GetDecisionFinishEventId())
+		}).
+		Return(&shared.ResetWorkflowExecutionResponse{RunId: common.StringPtr("new run ID")}, nil)
+
+	_, err := s.client.ResetWorkflow(context.Background(), &ResetWorkflowRequest{
+		WorkflowID: workflowID,
+		ResetPoint: ResetPointBadBinary("bad"),
+	})
+	s.NoError(err)
+
+	s.service.EXPECT().DescribeWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&shared.DescribeWorkflowExecutionResponse{WorkflowExecutionInfo: &shared.WorkflowExecutionInfo{}}, nil)
+	_, err = s.client.ResetWorkflow(context.Background(), &ResetWorkflowRequest{
+		WorkflowID: workflowID,
+		ResetPoint: ResetPointBadBinary("unknown"),
+	})
+	s.Error(err)
+}
+
+func (s *workflowClientTestSuite) TestResetWorkflow_EventID() {
+	s.service.EXPECT().ResetWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ interface{}, req *shared.ResetWorkflowExecutionRequest, _ ...interface{}) {
+			s.Nil(req.WorkflowExecution.RunId)
+			s.Equal(int64(10), req.GetDecisionFinishEventId())
+		}).
+		Return(&shared.ResetWorkflowExecutionResponse{RunId: common.StringPtr("new run ID")}, nil)
+
+	_, err := s.client.ResetWorkflow(context.Background(), &ResetWorkflowRequest{
+		WorkflowID: workflowID,
+		ResetPoint: ResetPointEventID(10),
+	})
+	s.NoError(err)
+
+	_, err = s.client.ResetWorkflow(context.Background(), &ResetWorkflowRequest{WorkflowID: workflowID})
+	s.EqualError(err, "reset point is required")
+}
+
+func (s *workflowClientTestSuite) TestResetWorkflows() {
+	newExecutionInfo := func(workflowID, runID string) *shared.WorkflowExecutionInfo {
+		return &shared.WorkflowExecutionInfo{
+			Execution: &shared.WorkflowExecution{WorkflowId: common.StringPtr(workflowID), RunId: common.StringPtr(runID)},
+		}
+	}
+	s.service.EXPECT().ListWorkflowExecutions(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ interface{}, req *shared.ListWorkflowExecutionsRequest, _ ...interface{}) {
+			s.Equal("CloseTime = missing", req.GetQuery())
+			s.Nil(req.NextPageToken)
+		}).
+		Return(&shared.ListWorkflowExecutionsResponse{
+			Executions:    []*shared.WorkflowExecutionInfo{newExecutionInfo("wid1", "rid1"), newExecutionInfo("wid2", "rid2")},
+			NextPageToken: []byte("token"),
+		}, nil)
+	s.service.EXPECT().ListWorkflowExecutions(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ interface{}, req *shared.ListWorkflowExecutionsRequest, _ ...interface{}) {
+			s.Equal([]byte("token"), req.NextPageToken)
+		}).
+		Return(&shared.ListWorkflowExecutionsResponse{
+			Executions: []*shared.WorkflowExecutionInfo{newExecutionInfo("wid3", "rid3")},
+		}, nil)
+
+	resetErr := &shared.EntityNotExistsError{}
+	s.service.EXPECT().ResetWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ interface{}, req *shared.ResetWorkflowExecutionRequest, _ ...interface{}) (*shared.ResetWorkflowExecutionResponse, error) {
+			s.Equal(int64(5), req.GetDecisionFinishEventId())
+			if req.WorkflowExecution.GetWorkflowId() == "wid2" {
+				return nil, resetErr
+			}
+			return &shared.ResetWorkflowExecutionResponse{RunId: common.StringPtr("new-" + req.WorkflowExecution.GetRunId())}, nil
+		}).Times(3)
+
+	response, err := s.client.ResetWorkflows(context.Background(), &ResetWorkflowsRequest{
+		Query:      "CloseTime = missing",
+		ResetPoint: ResetPointEventID(5),
+	})
+	s.NoError(err)
+	s.Equal(2, response.Succeeded)
+	s.Equal(1, response.Failed)
+	s.Equal([]BatchWorkflowFailure{{Execution: WorkflowExecution{ID: "wid2", RunID: "rid2"}, Err: resetErr}}, response.Failures)
+
+	_, err = s.client.ResetWorkflows(context.Background(), &ResetWorkflowsRequest{Query: "CloseTime = missing"})
+	s.EqualError(err, "reset point is required")
+}
+
+func (s *workflowClientTestSuite) TestBatchWorkflow() {
+	newExecutionInfo := func(workflowID string) *shared.WorkflowExecutionInfo {
+		return &shared.WorkflowExecutionInfo{
+			Execution: &shared.WorkflowExecution{WorkflowId: common.StringPtr(workflowID), RunId: common.StringPtr(runID)},
+		}
+	}
+	s.service.EXPECT().ListWorkflowExecutions(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ interface{}, req *shared.ListWorkflowExecutionsRequest, _ ...interface{}) {
+			s.Equal("CloseTime = missing", req.GetQuery())
+			s.Equal(int32(3), req.GetPageSize())
+			s.Nil(req.NextPageToken)
+		}).
+		Return(&shared.ListWorkflowExecutionsResponse{
+			Executions:    []*shared.WorkflowExecutionInfo{newExecutionInfo("wid1"), newExecutionInfo("wid2"), newExecutionInfo("wid3")},
+			NextPageToken: []byte("token"),
+		}, nil)
+	s.service.EXPECT().ListWorkflowExecutions(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ interface{}, req *shared.ListWorkflowExecutionsRequest, _ ...interface{}) {
+			s.Equal([]byte("token"), req.NextPageToken)
+		}).
+		Return(&shared.ListWorkflowExecutionsResponse{
+			Executions: []*shared.WorkflowExecutionInfo{newExecutionInfo("wid4")},
+		}, nil)
+
+	terminateErr := &shared.EntityNotExistsError{}
+	s.service.EXPECT().TerminateWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ interface{}, req *shared.TerminateWorkflowExecutionRequest, _ ...interface{}) error {
+			s.Equal("cleanup", req.GetReason())
+			if req.WorkflowExecution.GetWorkflowId() == "wid2" {
+				return terminateErr
+			}
+			return nil
+		}).Times(4)
+
+	var progress []BatchWorkflowProgress
+	response, err := s.client.BatchWorkflow(context.Background(), &BatchWorkflowRequest{
+		Query:       "CloseTime = missing",
+		Operation:   BatchTerminate("cleanup", nil),
+		Concurrency: 2,
+		RPS:         1000,
+		PageSize:    3,
+		ProgressHandler: func(p BatchWorkflowProgress) {
+			progress = append(progress, p)
+		},
+	})
+	s.NoError(err)
+	s.Equal(3, response.Succeeded)
+	s.Equal(1, response.Failed)
+	failure := BatchWorkflowFailure{Execution: WorkflowExecution{ID: "wid2", RunID: runID}, Err: terminateErr}
+	s.Equal([]BatchWorkflowFailure{failure}, response.Failures)
+
+	s.Len(progress, 2)
+	s.Equal(2, progress[0].Succeeded)
+	s.Equal(1, progress[0].Failed)
+	s.Equal([]BatchWorkflowFailure{failure}, progress[0].Failures)
+	s.Equal(3, progress[1].Succeeded)
+	s.Empty(progress[1].Failures)
+
+	// resuming a completed batch does not list executions again
+	response, err = s.client.BatchWorkflow(context.Background(), &BatchWorkflowRequest{
+		Query:      "CloseTime = missing",
+		Operation:  BatchTerminate("cleanup", nil),
+		Checkpoint: progress[1].Checkpoint,
+	})
+	s.NoError(err)
+	s.Equal(3, response.Succeeded)
+	s.Equal(1, response.Failed)
+}
+
+func (s *workflowClientTestSuite) TestBatchWorkflow_WithInterceptors() {
+	interceptor := &testClientInterceptorFactory{}
+	client := NewClient(s.service, domain, &ClientOptions{Interceptors: []ClientInterceptorFactory{interceptor}})
+	s.service.EXPECT().ListWorkflowExecutions(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&shared.ListWorkflowExecutionsResponse{
+			Executions: []*shared.WorkflowExecutionInfo{
+				{Execution: &shared.WorkflowExecution{WorkflowId: common.StringPtr("wid1"), RunId: common.StringPtr("rid1")}},
+				{Execution: &shared.WorkflowExecution{WorkflowId: common.StringPtr("wid2"), RunId: common.StringPtr("rid2")}},
+			},
+		}, nil)
+
+	response, err := client.BatchWorkflow(context.Background(), &BatchWorkflowRequest{
+		Query:       "CloseTime = missing",
+		Operation:   BatchSignal("forbidden", nil),
+		Concurrency: 1,
+	})
+	s.NoError(err)
+	s.Equal(0, response.Succeeded)
+	s.Equal(2, response.Failed)
+	s.EqualError(response.Failures[0].Err, "signal forbidden is not allowed")
+	s.Equal([]string{"SignalWorkflow forbidden", "SignalWorkflow forbidden"}, interceptor.instance.trace)
+}
+
+func (s *workflowClientTestSuite) TestBatchWorkflow_Resume() {
+	s.service.EXPECT().ListWorkflowExecutions(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&shared.ListWorkflowExecutionsResponse{
+			Executions: []*shared.WorkflowExecutionInfo{
+				{Execution: &shared.WorkflowExecution{WorkflowId: common.StringPtr("wid1"), RunId: common.StringPtr(runID)}},
+			},
+			NextPageToken: []byte("token"),
+		}, nil)
+	listErr := &shared.BadRequestError{}
+	s.service.EXPECT().ListWorkflowExecutions(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, listErr)
+	s.service.EXPECT().SignalWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ interface{}, req *shared.SignalWorkflowExecutionRequest, _ ...interface{}) {
+			s.Equal("wake-up", req.GetSignalName())
+		}).Return(nil).Times(2)
+
+	request := &BatchWorkflowRequest{
+		Query:     "WorkflowType = 'test'",
+		Operation: BatchSignal("wake-up", nil),
+	}
+	response, err := s.client.BatchWorkflow(context.Background(), request)
+	s.Equal(listErr, err)
+	s.Equal(1, response.Succeeded)
+	s.NotEmpty(response.Checkpoint)
+
+	s.service.EXPECT().ListWorkflowExecutions(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ interface{}, req *shared.ListWorkflowExecutionsRequest, _ ...interface{}) {
+			s.Equal([]byte("token"), req.NextPageToken)
+		}).
+		Return(&shared.ListWorkflowExecutionsResponse{
+			Executions: []*shared.WorkflowExecutionInfo{
+				{Execution: &shared.WorkflowExecution{WorkflowId: common.StringPtr("wid2"), RunId: common.StringPtr(runID)}},
+			},
+		}, nil)
+	request.Checkpoint = response.Checkpoint
+	response, err = s.client.BatchWorkflow(context.Background(), request)
+	s.NoError(err)
+	s.Equal(2, response.Succeeded)
+
+	request.Query = "WorkflowType = 'other'"
+	_, err = s.client.BatchWorkflow(context.Background(), request)
+	s.Error(err)
+}
+
+var _ ClientInterceptorFactory = (*testClientInterceptorFactory)(nil)
+
+type testClientInterceptorFactory struct {
+	instance *testClientInterceptor
+}
+
+func (f *testClientInterceptorFactory) NewInterceptor(next ClientInterceptor) ClientInterceptor {
+	f.instance = &testClientInterceptor{ClientInterceptorBase: ClientInterceptorBase{Next: next}}
+	return f.instance
+}
+
+type testClientInterceptor struct {
+	ClientInterceptorBase
+	trace []string
+}
+
+func (t *testClientInterceptor) StartWorkflow(ctx context.Context, options StartWorkflowOptions, workflow interface{}, args ...interface{}) (*WorkflowExecution, error) {
+	options.ID = "tenant-" + options.ID
+	execution, err := t.Next.StartWorkflow(ctx, options, workflow, args...)
+	if err == nil {
+		t.trace = append(t.trace, "StartWorkflow "+execution.ID+" "+execution.RunID)
+	}
+	return execution, err
+}
+
+func (t *testClientInterceptor) SignalWorkflow(ctx context.Context, workflowID string, runID string, signalName string, arg interface{}) error {
+	t.trace = append(t.trace, "SignalWorkflow "+signalName)
+	if signalName == "forbidden" {
+		return fmt.Errorf("signal %v is not allowed", signalName)
+	}
+	return t.Next.SignalWorkflow(ctx, workflowID, runID, signalName, arg)
+}
+
 func serializeEvents(events []*shared.HistoryEvent) *shared.DataBlob {
 
 	blob, _ := serializer.SerializeBatchEvents(events, shared.EncodingTypeThriftRW)
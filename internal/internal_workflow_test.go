@@ -1180,6 +1180,173 @@ func (s *WorkflowUnitTest) Test_WaitGroupWorkflowTest() {
 	s.Equal(n, total)
 }
 
+func mutexWorkflowTest(ctx Context, n int) ([]string, error) {
+	var trace []string
+	mutex := NewMutex(ctx)
+	waitGroup := NewWaitGroup(ctx)
+	for i := 0; i < n; i++ {
+		waitGroup.Add(1)
+		name := fmt.Sprintf("c%v", i)
+		Go(ctx, func(ctx Context) {
+			defer waitGroup.Done()
+			if err := mutex.Lock(ctx); err != nil {
+				return
+			}
+			trace = append(trace, name+" locked")
+			_ = Sleep(ctx, time.Minute)
+			trace = append(trace, name+" unlocked")
+			mutex.Unlock()
+		})
+	}
+	waitGroup.Wait(ctx)
+	if mutex.IsLocked() {
+		return nil, errors.New("mutex is still locked")
+	}
+	return trace, nil
+}
+
+func semaphoreWorkflowTest(ctx Context, size int64, n int) (int, error) {
+	running, maxRunning := 0, 0
+	semaphore := NewSemaphore(ctx, size)
+	waitGroup := NewWaitGroup(ctx)
+	for i := 0; i < n; i++ {
+		waitGroup.Add(1)
+		Go(ctx, func(ctx Context) {
+			defer waitGroup.Done()
+			if err := semaphore.Acquire(ctx, 1); err != nil {
+				return
+			}
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			_ = Sleep(ctx, time.Minute)
+			running--
+			semaphore.Release(1)
+		})
+	}
+	waitGroup.Wait(ctx)
+	if err := semaphore.Acquire(ctx, size+1); err == nil {
+		return 0, errors.New("acquired weight exceeding the semaphore size")
+	}
+	return maxRunning, nil
+}
+
+func (s *WorkflowUnitTest) Test_MutexWorkflowTest() {
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(mutexWorkflowTest)
+	env.RegisterDelayedCallback(func() {
+		result, err := env.QueryWorkflow(QueryTypeStackTrace)
+		s.NoError(err)
+		var stackTrace string
+		s.NoError(result.Get(&stackTrace))
+		s.Contains(stackTrace, "blocked on mutex-1.Lock")
+	}, time.Second)
+	env.ExecuteWorkflow(mutexWorkflowTest, 3)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+
+	var trace []string
+	s.NoError(env.GetWorkflowResult(&trace))
+	s.Equal([]string{"c0 locked", "c0 unlocked", "c1 locked", "c1 unlocked", "c2 locked", "c2 unlocked"}, trace)
+}
+
+func (s *WorkflowUnitTest) Test_MutexCanceledWorkflowTest() {
+	workflowFn := func(ctx Context) error {
+		mutex := NewMutex(ctx)
+		if !mutex.TryLock(ctx) {
+			return errors.New("unable to lock mutex")
+		}
+		if mutex.TryLock(ctx) {
+			return errors.New("locked mutex twice")
+		}
+		ctx, cancel := WithCancel(ctx)
+		Go(ctx, func(ctx Context) {
+			_ = Sleep(ctx, time.Minute)
+			cancel()
+		})
+		return mutex.Lock(ctx)
+	}
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.IsType(&CanceledError{}, env.GetWorkflowError())
+}
+
+func (s *WorkflowUnitTest) Test_SemaphoreWorkflowTest() {
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(semaphoreWorkflowTest)
+	env.ExecuteWorkflow(semaphoreWorkflowTest, int64(2), 5)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+
+	var maxRunning int
+	s.NoError(env.GetWorkflowResult(&maxRunning))
+	s.Equal(2, maxRunning)
+}
+
+func (s *WorkflowUnitTest) Test_SemaphoreInvalidWeightWorkflowTest() {
+	workflowFn := func(ctx Context) ([]string, error) {
+		semaphore := NewSemaphore(ctx, 2)
+		var errs []string
+		for _, n := range []int64{0, -1, 3} {
+			if err := semaphore.Acquire(ctx, n); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		return errs, nil
+	}
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var errs []string
+	s.NoError(env.GetWorkflowResult(&errs))
+	s.Equal([]string{
+		"semaphore-1: weight 0 must be positive",
+		"semaphore-1: weight -1 must be positive",
+		"semaphore-1: weight 3 exceeds the semaphore size 2",
+	}, errs)
+
+	zeroSizeWorkflowFn := func(ctx Context) error {
+		return NewSemaphore(ctx, 0).Acquire(ctx, 1)
+	}
+	env = s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(zeroSizeWorkflowFn)
+	env.ExecuteWorkflow(zeroSizeWorkflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.IsType(&PanicError{}, env.GetWorkflowError())
+	s.Contains(env.GetWorkflowError().Error(), "NewSemaphore: size 0 must be positive")
+}
+
+func (s *WorkflowUnitTest) Test_SemaphoreOverReleaseWorkflowTest() {
+	workflowFn := func(ctx Context) ([]string, error) {
+		semaphore := NewSemaphore(ctx, 2)
+		if err := semaphore.Acquire(ctx, 1); err != nil {
+			return nil, err
+		}
+		var result []string
+		func() {
+			defer func() {
+				result = append(result, fmt.Sprint(recover()))
+			}()
+			semaphore.Release(2)
+		}()
+		// the failed release leaves the held weight unchanged
+		return append(result, fmt.Sprint(semaphore.TryAcquire(ctx, 1)), fmt.Sprint(semaphore.TryAcquire(ctx, 1))), nil
+	}
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result []string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal([]string{"semaphore-1: released more than held", "true", "false"}, result)
+}
+
 var _ WorkflowInterceptorFactory = (*tracingInterceptorFactory)(nil)
 
 type tracingInterceptorFactory struct {
@@ -26,6 +26,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -71,6 +72,8 @@ type (
 		callback         resultHandler
 		activityType     string
 		heartbeatDetails []byte
+		cancellationType CancellationType
+		cancel           context.CancelFunc // cancels the context of the running activity
 	}
 
 	testWorkflowHandle struct {
@@ -197,7 +200,7 @@ var _ workflowEnvironment = (*testWorkflowEnvironmentImpl)(nil)
 func newTestWorkflowEnvironmentImpl(s *WorkflowTestSuite, parentRegistry *registry) *testWorkflowEnvironmentImpl {
 	var r *registry
 	if parentRegistry == nil {
-		r = newRegistry()
+		r = newRegistryWithOptions(registryOptions{disableGlobalRegistry: s.disableGlobalRegistry})
 		r.RegisterActivityWithOptions(sessionCreationActivity, RegisterActivityOptions{
 			Name: sessionCreationActivityName,
 		})
@@ -411,6 +414,7 @@ func (env *testWorkflowEnvironmentImpl) setWorkerOptions(options WorkerOptions)
 		env.workerOptions.ContextPropagators = options.ContextPropagators
 	}
 	env.workflowInterceptors = options.WorkflowInterceptorChainFactories
+	env.workerOptions.ActivityInterceptorChainFactories = options.ActivityInterceptorChainFactories
 }
 
 func (env *testWorkflowEnvironmentImpl) setWorkerStopChannel(c chan struct{}) {
@@ -450,6 +454,7 @@ func (env *testWorkflowEnvironmentImpl) executeWorkflowInternal(delayStart time.
 		panic(fmt.Sprintf("Current TestWorkflowEnvironment is used to execute %v. Please create a new TestWorkflowEnvironment for %v.", env.workflowInfo.WorkflowType.Name, workflowType))
 	}
 	env.workflowInfo.WorkflowType.Name = workflowType
+	env.workflowInfo.dataConverter = env.GetDataConverter()
 	env.locker.Unlock()
 
 	workflowDefinition, err := env.getWorkflowDefinition(env.workflowInfo.WorkflowType)
@@ -536,7 +541,8 @@ func (env *testWorkflowEnvironmentImpl) executeActivity(
 	task.HeartbeatDetails = env.heartbeatDetails
 
 	// ensure activityFn is registered to defaultTestTaskList
-	taskHandler := env.newTestActivityTaskHandler(defaultTestTaskList, env.GetDataConverter())
+	taskHandler, cancel := env.newTestActivityTaskHandler(defaultTestTaskList, env.GetDataConverter())
+	defer cancel()
 	result, err := taskHandler.Execute(defaultTestTaskList, task)
 	if err != nil {
 		if err == context.DeadlineExceeded {
@@ -589,6 +595,7 @@ func (env *testWorkflowEnvironmentImpl) executeLocalActivity(
 		metricsScope: env.metricsScope,
 		logger:       env.logger,
 		tracer:       opentracing.NoopTracer{},
+		interceptors: env.workerOptions.ActivityInterceptorChainFactories,
 	}
 
 	result := taskHandler.executeLocalActivityTask(task)
@@ -746,6 +753,24 @@ func (env *testWorkflowEnvironmentImpl) RequestCancelActivity(activityID string)
 	}
 	activityInfo := env.getActivityInfo(activityID, handle.activityType)
 	env.logger.Debug("RequestCancelActivity", zap.String(tagActivityID, activityID))
+	switch handle.cancellationType {
+	case CancellationTypeWaitCancellationCompleted:
+		// keep the handle, the workflow gets the result once the activity reacts to the cancellation
+		handle.cancel()
+		if env.onActivityCanceledListener != nil {
+			env.postCallback(func() {
+				env.onActivityCanceledListener(activityInfo)
+			}, false)
+		}
+		return
+	case CancellationTypeAbandon:
+		// the activity keeps running and is never notified about the cancellation
+		env.deleteHandle(activityID)
+		env.postCallback(func() {
+			handle.callback(nil, NewCanceledError())
+		}, true)
+		return
+	}
 	env.deleteHandle(activityID)
 	env.postCallback(func() {
 		handle.callback(nil, NewCanceledError())
@@ -945,8 +970,13 @@ func (env *testWorkflowEnvironmentImpl) ExecuteActivity(parameters executeActivi
 		parameters,
 	)
 
-	taskHandler := env.newTestActivityTaskHandler(parameters.TaskListName, parameters.DataConverter)
-	activityHandle := &testActivityHandle{callback: callback, activityType: parameters.ActivityType.Name}
+	taskHandler, cancel := env.newTestActivityTaskHandler(parameters.TaskListName, parameters.DataConverter)
+	activityHandle := &testActivityHandle{
+		callback:         callback,
+		activityType:     parameters.ActivityType.Name,
+		cancellationType: parameters.CancellationType,
+		cancel:           cancel,
+	}
 
 	env.setActivityHandle(activityInfo.activityID, activityHandle)
 	env.runningCount++
@@ -969,6 +999,7 @@ func (env *testWorkflowEnvironmentImpl) ExecuteActivity(parameters executeActivi
 					Details: details,
 				}
 			}
+			cancel()
 			// post activity result to workflow dispatcher
 			env.postCallback(func() {
 				env.handleActivityResult(activityInfo.activityID, result, parameters.ActivityType.Name, parameters.DataConverter)
@@ -1085,6 +1116,8 @@ func (env *testWorkflowEnvironmentImpl) ExecuteLocalActivity(params executeLocal
 
 	// substitute the local activity function so we could replace with mock if it is supplied.
 	params.ActivityFn = func(ctx context.Context, inputArgs ...interface{}) ([]byte, error) {
+		// interceptors wrap the actual local activity function rather than this substitute.
+		getActivityEnv(ctx).interceptors = wOptions.ActivityInterceptorChainFactories
 		return aew.ExecuteWithActualArgs(ctx, params.InputArgs)
 	}
 
@@ -1511,18 +1544,22 @@ func (m *mockWrapper) executeMockWithActualArgs(ctx interface{}, inputArgs []int
 	return m.getMockValue(mockRet)
 }
 
-func (env *testWorkflowEnvironmentImpl) newTestActivityTaskHandler(taskList string, dataConverter DataConverter) ActivityTaskHandler {
+func (env *testWorkflowEnvironmentImpl) newTestActivityTaskHandler(
+	taskList string,
+	dataConverter DataConverter,
+) (ActivityTaskHandler, context.CancelFunc) {
 	wOptions := augmentWorkerOptions(env.workerOptions)
 	params := workerExecutionParameters{
-		TaskList:           taskList,
-		Identity:           wOptions.Identity,
-		MetricsScope:       wOptions.MetricsScope,
-		Logger:             wOptions.Logger,
-		UserContext:        wOptions.BackgroundActivityContext,
-		DataConverter:      dataConverter,
-		WorkerStopChannel:  env.workerStopChannel,
-		ContextPropagators: wOptions.ContextPropagators,
-		Tracer:             wOptions.Tracer,
+		TaskList:             taskList,
+		Identity:             wOptions.Identity,
+		MetricsScope:         wOptions.MetricsScope,
+		Logger:               wOptions.Logger,
+		UserContext:          wOptions.BackgroundActivityContext,
+		DataConverter:        dataConverter,
+		WorkerStopChannel:    env.workerStopChannel,
+		ContextPropagators:   wOptions.ContextPropagators,
+		Tracer:               wOptions.Tracer,
+		ActivityInterceptors: wOptions.ActivityInterceptorChainFactories,
 	}
 	ensureRequiredParams(&params)
 	if params.UserContext == nil {
@@ -1532,8 +1569,11 @@ func (env *testWorkflowEnvironmentImpl) newTestActivityTaskHandler(taskList stri
 		env.sessionEnvironment = newTestSessionEnvironment(env, &params, wOptions.MaxConcurrentSessionExecutionSize)
 	}
 	params.UserContext = context.WithValue(params.UserContext, sessionEnvironmentContextKey, env.sessionEnvironment)
+	var cancel context.CancelFunc
+	params.UserContext, cancel = context.WithCancel(params.UserContext)
 	registry := env.registry
 	if len(registry.getRegisteredActivities()) == 0 {
+		cancel()
 		panic(fmt.Sprintf("no activity is registered for tasklist '%v'", taskList))
 	}
 
@@ -1552,6 +1592,9 @@ func (env *testWorkflowEnvironmentImpl) newTestActivityTaskHandler(taskList stri
 			return nil
 		}
 		ae := &activityExecutor{name: activity.ActivityType().Name, fn: activity.GetFunction()}
+		if a, ok := activity.(autoHeartbeatActivity); ok {
+			ae.autoHeartbeat = a.isAutoHeartbeatEnabled()
+		}
 
 		// Special handling for session creation and completion activities.
 		// If real creation activity is used, it will block timers from autofiring.
@@ -1566,7 +1609,7 @@ func (env *testWorkflowEnvironmentImpl) newTestActivityTaskHandler(taskList stri
 	}
 
 	taskHandler := newActivityTaskHandlerWithCustomProvider(env.service, params, registry, getActivity)
-	return taskHandler
+	return taskHandler, cancel
 }
 
 func newTestActivityTask(workflowID, runID, activityID, workflowTypeName, domainName string, params executeActivityParams) *shared.PollForActivityTaskResponse {
@@ -1662,6 +1705,10 @@ func (env *testWorkflowEnvironmentImpl) RegisterQueryHandler(handler func(string
 
 func (env *testWorkflowEnvironmentImpl) RequestCancelChildWorkflow(domainName, workflowID string) {
 	if childHandle, ok := env.runningWorkflows[workflowID]; ok && !childHandle.handled {
+		if childHandle.params.cancellationType == CancellationTypeAbandon {
+			// abandoned child workflows keep running without being notified
+			return
+		}
 		// current workflow is a parent workflow, and we are canceling a child workflow
 		childEnv := childHandle.env
 		childEnv.cancelWorkflow(func(result []byte, err error) {})
@@ -1946,6 +1993,12 @@ func (env *testWorkflowEnvironmentImpl) signalWorkflowByID(workflowID, signalNam
 }
 
 func (env *testWorkflowEnvironmentImpl) queryWorkflow(queryType string, args ...interface{}) (Value, error) {
+	switch queryType {
+	case QueryTypeStackTrace:
+		return env.queryStackTrace()
+	case QueryTypePendingOperations:
+		return env.queryPendingOperations()
+	}
 	data, err := encodeArgs(env.GetDataConverter(), args)
 	if err != nil {
 		return nil, err
@@ -1957,6 +2010,94 @@ func (env *testWorkflowEnvironmentImpl) queryWorkflow(queryType string, args ...
 	return newEncodedValue(blob, env.GetDataConverter()), nil
 }
 
+func (env *testWorkflowEnvironmentImpl) queryStackTrace() (Value, error) {
+	blob, err := encodeArg(env.GetDataConverter(), env.workflowDef.StackTrace())
+	if err != nil {
+		return nil, err
+	}
+	return newEncodedValue(blob, env.GetDataConverter()), nil
+}
+
+// queryPendingOperations lists the activities, timers and child workflows the test environment runs for the workflow.
+// Requests to external workflows are handled right away by the test environment and are never pending.
+func (env *testWorkflowEnvironmentImpl) queryPendingOperations() (Value, error) {
+	var result PendingOperations
+	activityPrefix := env.makeUniqueID("")
+	for id, handle := range env.activities {
+		if strings.HasPrefix(id, activityPrefix) {
+			result.Activities = append(result.Activities, PendingActivityInfo{
+				ActivityID:   strings.TrimPrefix(id, activityPrefix),
+				ActivityType: handle.activityType,
+				State:        decisionStateStarted.String(),
+			})
+		}
+	}
+	for id, handle := range env.timers {
+		if handle.env == env {
+			result.Timers = append(result.Timers, PendingTimerInfo{
+				TimerID:            id,
+				StartToFireTimeout: handle.duration,
+				State:              decisionStateInitiated.String(),
+			})
+		}
+	}
+	for id, handle := range env.runningWorkflows {
+		if handle.env.parentEnv == env && !handle.handled {
+			result.ChildWorkflows = append(result.ChildWorkflows, PendingChildWorkflowInfo{
+				Domain:       handle.env.workflowInfo.Domain,
+				WorkflowID:   id,
+				WorkflowType: handle.env.workflowInfo.WorkflowType.Name,
+				State:        decisionStateStarted.String(),
+			})
+		}
+	}
+	sort.Slice(result.Activities, func(i, j int) bool {
+		return result.Activities[i].ActivityID < result.Activities[j].ActivityID
+	})
+	sort.Slice(result.Timers, func(i, j int) bool {
+		return result.Timers[i].TimerID < result.Timers[j].TimerID
+	})
+	sort.Slice(result.ChildWorkflows, func(i, j int) bool {
+		return result.ChildWorkflows[i].WorkflowID < result.ChildWorkflows[j].WorkflowID
+	})
+	result.QueryHandlers, result.SignalHandlers = env.workflowDef.HandlerNames()
+
+	blob, err := encodeArg(env.GetDataConverter(), result)
+	if err != nil {
+		return nil, err
+	}
+	return newEncodedValue(blob, env.GetDataConverter()), nil
+}
+
+func (env *testWorkflowEnvironmentImpl) updateWorkflow(updateName, updateID string, args ...interface{}) {
+	data, err := encodeArgs(env.GetDataConverter(), args)
+	if err != nil {
+		panic(err)
+	}
+	env.signalWorkflow(updateSignalName, updateRequest{ID: updateID, Name: updateName, Args: data}, true)
+}
+
+func (env *testWorkflowEnvironmentImpl) getUpdateResult(updateID string) (Value, error) {
+	result, err := env.queryWorkflow(updateQueryType, updateID)
+	if err != nil {
+		return nil, err
+	}
+	var outcome updateOutcome
+	if err := result.Get(&outcome); err != nil {
+		return nil, err
+	}
+	if outcome.Unknown {
+		return nil, fmt.Errorf("update %v is unknown", updateID)
+	}
+	if !outcome.Completed {
+		return nil, fmt.Errorf("update %v has not completed", updateID)
+	}
+	if err := outcome.error(env.GetDataConverter()); err != nil {
+		return nil, err
+	}
+	return newEncodedValue(outcome.Result, env.GetDataConverter()), nil
+}
+
 func (env *testWorkflowEnvironmentImpl) getMockRunFn(callWrapper *MockCallWrapper) func(args mock.Arguments) {
 	env.locker.Lock()
 	defer env.locker.Unlock()
@@ -31,11 +31,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/cadence/.gen/go/shared"
 	"go.uber.org/cadence/internal/common"
+	"go.uber.org/cadence/internal/common/metrics"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 type WorkflowTestSuiteUnitTest struct {
@@ -218,6 +221,77 @@ func (s *WorkflowTestSuiteUnitTest) Test_TimerWorkflow_ClockAutoFastForward() {
 	s.Equal([]string{"t2", "t3", "t1", "t4"}, firedTimerRecord)
 }
 
+func (s *WorkflowTestSuiteUnitTest) Test_WorkflowWithTimeout() {
+	workflowFn := func(ctx Context) (string, error) {
+		start := Now(ctx)
+		timeoutCtx, cancel := WithTimeout(ctx, time.Minute)
+		defer cancel()
+
+		deadline, ok := timeoutCtx.Deadline()
+		if !ok || !deadline.Equal(start.Add(time.Minute)) {
+			return "", fmt.Errorf("unexpected deadline %v", deadline)
+		}
+
+		// the shorter deadline of the parent wins
+		childCtx, childCancel := WithTimeout(timeoutCtx, time.Hour)
+		defer childCancel()
+		if childDeadline, _ := childCtx.Deadline(); !childDeadline.Equal(deadline) {
+			return "", fmt.Errorf("unexpected child deadline %v", childDeadline)
+		}
+
+		err := Sleep(childCtx, time.Hour)
+		if _, ok := err.(*CanceledError); !ok {
+			return "", fmt.Errorf("unexpected sleep error %v", err)
+		}
+		if Now(ctx).Sub(start) != time.Minute {
+			return "", fmt.Errorf("deadline fired after %v", Now(ctx).Sub(start))
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return fmt.Sprintf("%v %v", timeoutCtx.Err() == ErrDeadlineExceeded, childCtx.Err() == ErrDeadlineExceeded), nil
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal("true true", result)
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_WorkflowWithDeadline_CanceledBeforeDeadline() {
+	workflowFn := func(ctx Context) (string, error) {
+		deadlineCtx, cancel := WithDeadline(ctx, Now(ctx).Add(time.Hour))
+		Go(ctx, func(ctx Context) {
+			_ = Sleep(ctx, time.Minute)
+			cancel()
+		})
+		deadlineCtx.Done().Receive(ctx, nil)
+		if _, ok := deadlineCtx.Err().(*CanceledError); !ok {
+			return "", fmt.Errorf("unexpected error %v", deadlineCtx.Err())
+		}
+		// the deadline timer is canceled together with the context
+		if err := Sleep(ctx, 2*time.Hour); err != nil {
+			return "", err
+		}
+		return "canceled", nil
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal("canceled", result)
+}
+
 func (s *WorkflowTestSuiteUnitTest) Test_WorkflowAutoForwardClock() {
 	workflowFn := func(ctx Context) (string, error) {
 		// Schedule a timer with long duration. In this test, we won't actually wait for that long, because the test suite
@@ -348,6 +422,64 @@ func (s *WorkflowTestSuiteUnitTest) Test_WorkflowActivityCancellation() {
 	s.Equal(activityMap["slow"], cancelledActivityID)
 }
 
+func (s *WorkflowTestSuiteUnitTest) Test_ActivityCancellationType() {
+	cleanupActivityFn := func(ctx context.Context) (string, error) {
+		select {
+		case <-ctx.Done():
+			return "cleaned up", nil
+		case <-time.After(3 * time.Second):
+			return "completed", nil
+		}
+	}
+	workflowFn := func(ctx Context, cancellationType CancellationType) (string, error) {
+		ctx = WithActivityOptions(ctx, s.activityOptions)
+		ctx = WithCancellationType(ctx, cancellationType)
+
+		ctx, cancelHandler := WithCancel(ctx)
+		f1 := ExecuteActivity(ctx, testActivityHeartbeat, "fast", time.Millisecond)
+		f2 := ExecuteActivity(ctx, cleanupActivityFn)
+		if err := f1.Get(ctx, nil); err != nil {
+			return "", err
+		}
+		cancelHandler()
+
+		var result string
+		err := f2.Get(ctx, &result)
+		if _, ok := err.(*CanceledError); ok {
+			return "canceled", nil
+		}
+		return result, err
+	}
+
+	testCases := []struct {
+		cancellationType CancellationType
+		expectedResult   string
+		expectedCanceled bool
+	}{
+		{CancellationTypeTryCancel, "canceled", true},
+		{CancellationTypeWaitCancellationCompleted, "cleaned up", true},
+		{CancellationTypeAbandon, "canceled", false},
+	}
+	for _, tc := range testCases {
+		env := s.NewTestWorkflowEnvironment()
+		env.RegisterWorkflow(workflowFn)
+		env.RegisterActivity(testActivityHeartbeat)
+		env.RegisterActivity(cleanupActivityFn)
+		canceled := false
+		env.SetOnActivityCanceledListener(func(activityInfo *ActivityInfo) {
+			canceled = true
+		})
+		env.ExecuteWorkflow(workflowFn, tc.cancellationType)
+
+		s.True(env.IsWorkflowCompleted())
+		s.NoError(env.GetWorkflowError())
+		var result string
+		s.NoError(env.GetWorkflowResult(&result))
+		s.Equal(tc.expectedResult, result)
+		s.Equal(tc.expectedCanceled, canceled)
+	}
+}
+
 func (s *WorkflowTestSuiteUnitTest) Test_ActivityWithUserContext() {
 	testKey, testValue := testContextKey("test_key"), "test_value"
 	userCtx := context.WithValue(context.Background(), testKey, testValue)
@@ -1436,6 +1568,28 @@ func (s *WorkflowTestSuiteUnitTest) Test_ActivityWithThriftTypes() {
 	s.EqualValues(expectedValues, actualValues)
 }
 
+func (s *WorkflowTestSuiteUnitTest) Test_WorkflowWithProtoTypes() {
+	activityFn := func(ctx context.Context, name *wrapperspb.StringValue, count *wrapperspb.Int64Value) (*wrapperspb.StringValue, error) {
+		return wrapperspb.String(fmt.Sprintf("%v-%v", name.GetValue(), count.GetValue())), nil
+	}
+	workflowFn := func(ctx Context, name *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+		ctx = WithActivityOptions(ctx, s.activityOptions)
+		var result *wrapperspb.StringValue
+		err := ExecuteActivity(ctx, activityFn, name, wrapperspb.Int64(2)).Get(ctx, &result)
+		return result, err
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.RegisterActivity(activityFn)
+	env.ExecuteWorkflow(workflowFn, wrapperspb.String("proto"))
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result *wrapperspb.StringValue
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal("proto-2", result.GetValue())
+}
+
 func (s *WorkflowTestSuiteUnitTest) Test_ActivityRegistration() {
 	activityFn := func(msg string) (string, error) {
 		return msg, nil
@@ -1717,6 +1871,272 @@ func (s *WorkflowTestSuiteUnitTest) Test_QueryWorkflow() {
 	verifyStateWithQuery(stateDone)
 }
 
+func (s *WorkflowTestSuiteUnitTest) Test_UpdateWorkflow() {
+	workflowFn := func(ctx Context) (int, error) {
+		total := 0
+		err := SetUpdateHandler(ctx, "add", func(ctx Context, value int) (int, error) {
+			if value > 10 {
+				return 0, NewCustomError("too-large", value)
+			}
+			ctx = WithActivityOptions(ctx, s.activityOptions)
+			if err := ExecuteActivity(ctx, testActivityHello, "update").Get(ctx, nil); err != nil {
+				return 0, err
+			}
+			total += value
+			return total, nil
+		}, UpdateHandlerOptions{
+			Validator: func(ctx Context, value int) error {
+				if value < 0 {
+					return errors.New("negative value")
+				}
+				return nil
+			},
+		})
+		if err != nil {
+			return 0, err
+		}
+		GetSignalChannel(ctx, "done").Receive(ctx, nil)
+		return total, nil
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.RegisterActivity(testActivityHello)
+	env.OnActivity(testActivityHello, mock.Anything, "update").After(time.Minute).Return("hello_mock", nil).Once()
+
+	env.RegisterDelayedCallback(func() {
+		env.UpdateWorkflow("add", "update-1", 2)
+		env.UpdateWorkflow("add", "update-1", 2)
+		env.UpdateWorkflow("add", "update-2", -1)
+		env.UpdateWorkflow("add", "update-3", 20)
+		env.UpdateWorkflow("subtract", "update-4", 1)
+	}, time.Minute)
+	env.RegisterDelayedCallback(func() {
+		_, err := env.GetUpdateResult("update-1")
+		s.Error(err)
+	}, time.Minute+time.Second)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow("done", nil)
+	}, time.Hour)
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	env.AssertExpectations(s.T())
+	var total int
+	s.NoError(env.GetWorkflowResult(&total))
+	s.Equal(2, total)
+
+	result, err := env.GetUpdateResult("update-1")
+	s.NoError(err)
+	var value int
+	s.NoError(result.Get(&value))
+	s.Equal(2, value)
+
+	_, err = env.GetUpdateResult("update-2")
+	s.IsType(&UpdateRejectedError{}, err)
+	s.Contains(err.Error(), "negative value")
+
+	_, err = env.GetUpdateResult("update-3")
+	customErr, ok := err.(*CustomError)
+	s.True(ok)
+	s.Equal("too-large", customErr.Reason())
+
+	_, err = env.GetUpdateResult("update-4")
+	s.IsType(&UpdateRejectedError{}, err)
+	s.Contains(err.Error(), "unknown update subtract")
+
+	_, err = env.GetUpdateResult("update-5")
+	s.Error(err)
+	s.Contains(err.Error(), "update update-5 is unknown")
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_UpdateOutcomesEviction() {
+	d := &updateDispatcher{
+		handlers: make(map[string]*updateHandler),
+		outcomes: make(map[string]*updateOutcome),
+	}
+	dc := getDefaultDataConverter()
+	for i := 0; i < maxCompletedUpdateOutcomes+10; i++ {
+		// unknown updates are rejected and completed right away
+		d.dispatch(updateRequest{ID: fmt.Sprintf("update-%v", i), Name: "unknown"}, dc)
+	}
+	s.Len(d.outcomes, maxCompletedUpdateOutcomes)
+	s.Len(d.completed, maxCompletedUpdateOutcomes)
+	s.NotContains(d.outcomes, "update-9")
+	s.Contains(d.outcomes, "update-10")
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_SignalHandler() {
+	workflowFn := func(ctx Context) ([]string, error) {
+		var events []string
+		err := SetSignalHandler(ctx, "a", func(ctx Context, value string) {
+			events = append(events, "a:"+value)
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = SetSignalHandler(ctx, "b", func(ctx Context, value int) {
+			_ = Sleep(ctx, time.Minute)
+			events = append(events, fmt.Sprintf("b:%v", value))
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := Await(ctx, func() bool { return len(events) == 4 }); err != nil {
+			return nil, err
+		}
+		return append(events, GetUnhandledSignalNames(ctx)...), nil
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow("a", "1")
+	}, 0)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflowSkippingDecision("b", 2)
+		env.SignalWorkflowSkippingDecision("a", "3")
+		env.SignalWorkflowSkippingDecision("c", "unhandled")
+		env.SignalWorkflow("b", 4)
+	}, time.Hour)
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result []string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal([]string{"a:1", "b:2", "a:3", "b:4", "c"}, result)
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_SignalHandler_CorruptSignal() {
+	workflowFn := func(ctx Context) ([]int, error) {
+		var values []int
+		err := SetSignalHandler(ctx, "a", func(ctx Context, value int) {
+			values = append(values, value)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := Await(ctx, func() bool { return len(values) == 1 }); err != nil {
+			return nil, err
+		}
+		return values, nil
+	}
+
+	// a separate suite keeps the metrics scope from leaking into the other tests
+	scope, closer, reporter := metrics.NewTaggedMetricsScope()
+	var testSuite WorkflowTestSuite
+	testSuite.SetMetricsScope(scope)
+	env := testSuite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow("a", "wrong")
+	}, time.Second)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow("a", 1)
+	}, time.Minute)
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result []int
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal([]int{1}, result)
+
+	closer.Close()
+	counts := reporter.Counts()
+	s.Len(counts, 1)
+	s.EqualValues(metrics.CorruptedSignalsCounter, counts[0].Name())
+	s.EqualValues(1, counts[0].Value())
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_SignalHandler_AfterCancellation() {
+	workflowFn := func(ctx Context) (string, error) {
+		var compensation string
+		err := SetSignalHandler(ctx, "compensate", func(ctx Context, value string) {
+			_ = Sleep(ctx, time.Minute)
+			compensation = value
+		})
+		if err != nil {
+			return "", err
+		}
+		if err := Sleep(ctx, time.Hour); err == nil {
+			return "", errors.New("workflow was not canceled")
+		}
+		ctx, _ = NewDisconnectedContext(ctx)
+		if _, err := AwaitWithTimeout(ctx, time.Hour, func() bool { return compensation != "" }); err != nil {
+			return "", err
+		}
+		return compensation, nil
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.RegisterDelayedCallback(env.CancelWorkflow, time.Minute)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow("compensate", "refund")
+	}, 2*time.Minute)
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal("refund", result)
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_QueryPendingOperations() {
+	workflowFn := func(ctx Context) error {
+		if err := SetQueryHandler(ctx, "state", func() (string, error) { return "", nil }); err != nil {
+			return err
+		}
+		if err := SetUpdateHandler(ctx, "rename", func(ctx Context, name string) error { return nil }, UpdateHandlerOptions{}); err != nil {
+			return err
+		}
+		if err := SetSignalHandler(ctx, "handled", func(ctx Context, value string) {}); err != nil {
+			return err
+		}
+		GetSignalChannel(ctx, "listened")
+		return Sleep(ctx, time.Hour)
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow("unlistened", "value")
+	}, time.Minute)
+	var result PendingOperations
+	env.RegisterDelayedCallback(func() {
+		encoded, err := env.QueryWorkflow(QueryTypePendingOperations)
+		s.NoError(err)
+		s.NoError(encoded.Get(&result))
+	}, 2*time.Minute)
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	s.Len(result.Timers, 1)
+	s.Equal(time.Hour, result.Timers[0].StartToFireTimeout)
+	s.Equal("Initiated", result.Timers[0].State)
+	s.Equal([]string{"state"}, result.QueryHandlers)
+	s.Equal([]string{"handled", "listened"}, result.SignalHandlers)
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_SignalHandler_InvalidHandler() {
+	workflowFn := func(ctx Context) error {
+		return SetSignalHandler(ctx, "a", func(value string) {})
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	s.Error(env.GetWorkflowError())
+	s.Contains(env.GetWorkflowError().Error(), "workflow.Context")
+}
+
 func (s *WorkflowTestSuiteUnitTest) Test_WorkflowWithLocalActivity() {
 	localActivityFn := func(ctx context.Context, name string) (string, error) {
 		return "hello " + name, nil
@@ -1741,6 +2161,39 @@ func (s *WorkflowTestSuiteUnitTest) Test_WorkflowWithLocalActivity() {
 	s.Equal("hello local_activity", result)
 }
 
+func (s *WorkflowTestSuiteUnitTest) Test_WorkflowWithLocalActivityInterceptor() {
+	localActivityFn := func(ctx context.Context, name string) (string, error) {
+		return "hello " + name, nil
+	}
+
+	workflowFn := func(ctx Context) (string, error) {
+		ctx = WithLocalActivityOptions(ctx, s.localActivityOptions)
+		var result string
+		f := ExecuteLocalActivity(ctx, localActivityFn, "local_activity")
+		err := f.Get(ctx, &result)
+		return result, err
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	tracer := &activityTracingInterceptorFactory{}
+	env.SetWorkerOptions(WorkerOptions{ActivityInterceptorChainFactories: []ActivityInterceptorFactory{tracer}})
+	env.RegisterWorkflow(workflowFn)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result string
+	err := env.GetWorkflowResult(&result)
+	s.NoError(err)
+	s.Equal("hello local_activity", result)
+
+	s.Equal(1, len(tracer.instances))
+	activityType := getFunctionName(localActivityFn)
+	s.Equal([]string{
+		"ExecuteActivity " + activityType + " begin",
+		"ExecuteActivity " + activityType + " end",
+	}, tracer.instances[0].trace)
+}
+
 func (s *WorkflowTestSuiteUnitTest) Test_LocalActivity() {
 	localActivityFn := func(ctx context.Context, name string) (string, error) {
 		return "hello " + name, nil
@@ -1982,6 +2435,48 @@ func (s *WorkflowTestSuiteUnitTest) Test_CancelChildWorkflow() {
 	s.NoError(env.GetWorkflowError())
 }
 
+func (s *WorkflowTestSuiteUnitTest) Test_AbandonChildWorkflow() {
+	childWorkflowFn := func(ctx Context) error {
+		return Sleep(ctx, 10*time.Second)
+	}
+
+	workflowFn := func(ctx Context) error {
+		cwo := ChildWorkflowOptions{
+			Domain:                       "test-domain",
+			ExecutionStartToCloseTimeout: time.Minute,
+			CancellationType:             CancellationTypeAbandon,
+		}
+
+		childCtx := WithChildWorkflowOptions(ctx, cwo)
+		childCtx, cancel := WithCancel(childCtx)
+		childFuture := ExecuteChildWorkflow(childCtx, childWorkflowFn)
+		Sleep(ctx, 2*time.Second)
+		cancel()
+
+		err := childFuture.Get(ctx, nil)
+		if _, ok := err.(*CanceledError); !ok {
+			return fmt.Errorf("Abandoned child workflow should receive CanceledError, instead got: %v", err)
+		}
+		// give the abandoned child workflow time to complete
+		return Sleep(ctx, 20*time.Second)
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(childWorkflowFn)
+	env.RegisterWorkflow(workflowFn)
+	var childErr error
+	childCompleted := false
+	env.SetOnChildWorkflowCompletedListener(func(workflowInfo *WorkflowInfo, result Value, err error) {
+		childCompleted = true
+		childErr = err
+	})
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	s.True(childCompleted)
+	s.NoError(childErr)
+}
+
 func (s *WorkflowTestSuiteUnitTest) Test_CancelExternalWorkflow() {
 	workflowFn := func(ctx Context) error {
 		// set domain to be more specific
@@ -3018,3 +3513,99 @@ func (s *WorkflowTestSuiteUnitTest) Test_AwaitWithTimeout() {
 	_ = env.GetWorkflowResult(&result)
 	s.False(result)
 }
+
+func (s *WorkflowTestSuiteUnitTest) Test_AwaitWithTimeout_ConditionMet() {
+	workflowFn := func(ctx Context) (bool, error) {
+		value := false
+		Go(ctx, func(ctx Context) {
+			GetSignalChannel(ctx, "signal").Receive(ctx, nil)
+			value = true
+		})
+		start := Now(ctx)
+		ok, err := AwaitWithTimeout(ctx, time.Hour, func() bool { return value })
+		if err != nil {
+			return false, err
+		}
+		if Now(ctx).Sub(start) >= time.Hour {
+			return false, errors.New("waited for the whole timeout")
+		}
+		return ok, nil
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow("signal", nil)
+	}, time.Minute)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result bool
+	s.NoError(env.GetWorkflowResult(&result))
+	s.True(result)
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_AwaitWithTimeout_TimerFired() {
+	workflowFn := func(ctx Context) (time.Duration, error) {
+		start := Now(ctx)
+		ok, err := AwaitWithTimeout(ctx, time.Hour, func() bool { return false })
+		if err != nil || ok {
+			return 0, fmt.Errorf("unexpected result %v, %v", ok, err)
+		}
+		return Now(ctx).Sub(start), nil
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var elapsed time.Duration
+	s.NoError(env.GetWorkflowResult(&elapsed))
+	s.Equal(time.Hour, elapsed)
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_AwaitWithTimeout_Canceled() {
+	workflowFn := func(ctx Context) error {
+		_, err := AwaitWithTimeout(ctx, time.Hour, func() bool { return false })
+		return err
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.RegisterDelayedCallback(func() {
+		env.CancelWorkflow()
+	}, time.Minute)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.IsType(&CanceledError{}, env.GetWorkflowError())
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_NewRandomAndNewUUID() {
+	workflowFn := func(ctx Context) ([]string, error) {
+		r1 := NewRandom(ctx)
+		r2 := NewRandom(ctx)
+		if r1.Int63() == r2.Int63() {
+			return nil, errors.New("generators share the same sequence")
+		}
+		return []string{NewUUID(ctx), NewUUID(ctx)}, nil
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var ids []string
+	s.NoError(env.GetWorkflowResult(&ids))
+	s.Len(ids, 2)
+	s.NotEqual(ids[0], ids[1])
+	for _, id := range ids {
+		parsed := uuid.Parse(id)
+		s.NotNil(parsed)
+		version, ok := parsed.Version()
+		s.True(ok)
+		s.Equal(uuid.Version(4), version)
+		s.Equal(uuid.RFC4122, parsed.Variant())
+	}
+}
@@ -24,6 +24,7 @@ package internal
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -33,14 +34,44 @@ var once sync.Once
 // Singleton to hold the host registration details.
 var globalRegistry *registry
 
+type (
+	// RegisteredWorkflowInfo describes a workflow function registered with a worker.
+	RegisteredWorkflowInfo struct {
+		// WorkflowType is the name the workflow is registered under.
+		WorkflowType WorkflowType
+		// Fn is the registered workflow function.
+		Fn interface{}
+	}
+
+	// RegisteredActivityInfo describes an activity function registered with a worker.
+	RegisteredActivityInfo struct {
+		// ActivityType is the name the activity is registered under.
+		ActivityType ActivityType
+		// Fn is the registered activity function, or the method value for activities registered through a structure.
+		Fn interface{}
+	}
+
+	registryOptions struct {
+		// disableGlobalRegistry stops the registry from falling back to the global registry.
+		disableGlobalRegistry bool
+	}
+)
+
 func newRegistry() *registry {
-	return &registry{
+	return newRegistryWithOptions(registryOptions{})
+}
+
+func newRegistryWithOptions(options registryOptions) *registry {
+	r := &registry{
 		workflowFuncMap:  make(map[string]interface{}),
 		workflowAliasMap: make(map[string]string),
 		activityFuncMap:  make(map[string]activity),
 		activityAliasMap: make(map[string]string),
-		next:             getGlobalRegistry(),
 	}
+	if !options.disableGlobalRegistry {
+		r.next = getGlobalRegistry()
+	}
+	return r
 }
 
 func getGlobalRegistry() *registry {
@@ -137,7 +168,7 @@ func (r *registry) registerActivityFunction(af interface{}, options RegisterActi
 			return fmt.Errorf("activity type \"%v\" is already registered", registerName)
 		}
 	}
-	r.activityFuncMap[registerName] = &activityExecutor{registerName, af}
+	r.activityFuncMap[registerName] = &activityExecutor{name: registerName, fn: af, autoHeartbeat: options.EnableAutoHeartbeat}
 	if len(alias) > 0 {
 		r.activityAliasMap[fnName] = alias
 	}
@@ -170,7 +201,11 @@ func (r *registry) registerActivityStruct(aStruct interface{}, options RegisterA
 				return fmt.Errorf("activity type \"%v\" is already registered", registerName)
 			}
 		}
-		r.activityFuncMap[registerName] = &activityExecutor{registerName, methodValue.Interface()}
+		r.activityFuncMap[registerName] = &activityExecutor{
+			name:          registerName,
+			fn:            methodValue.Interface(),
+			autoHeartbeat: options.EnableAutoHeartbeat,
+		}
 		if len(structPrefix) > 0 {
 			r.activityAliasMap[methodName] = registerName
 		}
@@ -271,6 +306,48 @@ func (r *registry) getRegisteredActivities() []activity {
 	return activities
 }
 
+// listWorkflows returns the workflows visible through the registry sorted by type name.
+// Registrations shadow the ones with the same name further down the chain.
+func (r *registry) listWorkflows() []RegisteredWorkflowInfo {
+	var result []RegisteredWorkflowInfo
+	seen := make(map[string]bool)
+	for current := r; current != nil; current = current.next {
+		current.Lock()
+		for name, fn := range current.workflowFuncMap {
+			if !seen[name] {
+				seen[name] = true
+				result = append(result, RegisteredWorkflowInfo{WorkflowType: WorkflowType{Name: name}, Fn: fn})
+			}
+		}
+		current.Unlock()
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].WorkflowType.Name < result[j].WorkflowType.Name
+	})
+	return result
+}
+
+// listActivities returns the activities visible through the registry sorted by type name.
+// Registrations shadow the ones with the same name further down the chain.
+func (r *registry) listActivities() []RegisteredActivityInfo {
+	var result []RegisteredActivityInfo
+	seen := make(map[string]bool)
+	for current := r; current != nil; current = current.next {
+		current.Lock()
+		for name, a := range current.activityFuncMap {
+			if !seen[name] {
+				seen[name] = true
+				result = append(result, RegisteredActivityInfo{ActivityType: a.ActivityType(), Fn: a.GetFunction()})
+			}
+		}
+		current.Unlock()
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ActivityType.Name < result[j].ActivityType.Name
+	})
+	return result
+}
+
 func (r *registry) getWorkflowDefinition(wt WorkflowType) (workflowDefinition, error) {
 	lookup := getFunctionName(wt.Name)
 	if alias, ok := r.getWorkflowAlias(lookup); ok {
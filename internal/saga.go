@@ -0,0 +1,150 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// SagaOptions configure how a Saga runs its compensations.
+	SagaOptions struct {
+		// ParallelCompensation runs all compensations concurrently. By default the compensations run one
+		// at a time in the reverse order they were added.
+		ParallelCompensation bool
+
+		// ContinueWithError keeps running the remaining compensations when one of them fails. By default
+		// sequential compensation stops at the first failure. Parallel compensations always all run.
+		ContinueWithError bool
+	}
+
+	// Saga records compensations as the steps of a workflow succeed and runs them when the workflow has to
+	// undo its work, for example:
+	//  saga := workflow.NewSaga(ctx, workflow.SagaOptions{})
+	//  defer func() {
+	//    if err != nil {
+	//      _ = saga.Compensate()
+	//    }
+	//  }()
+	//
+	//  if err = workflow.ExecuteActivity(ctx, ReserveInventory, order).Get(ctx, nil); err != nil {
+	//    return err
+	//  }
+	//  saga.AddCompensation(ReleaseInventory, order)
+	//
+	//  if err = workflow.ExecuteActivity(ctx, ChargePayment, order).Get(ctx, nil); err != nil {
+	//    return err
+	//  }
+	//  saga.AddCompensation(RefundPayment, order)
+	// Compensations run in a context created with NewDisconnectedContext, so they are executed even when the
+	// workflow is cancelled. A Saga must only be used by the workflow that created it.
+	Saga struct {
+		ctx           Context
+		options       SagaOptions
+		compensations []func(ctx Context) error
+		compensated   bool
+	}
+
+	// CompensationError is returned by Saga.Compensate when compensations fail.
+	CompensationError struct {
+		// Errors of the failed compensations in the order they completed.
+		Errors []error
+	}
+)
+
+// NewSaga creates a Saga. Compensation activities are scheduled with the activity options of ctx.
+func NewSaga(ctx Context, options SagaOptions) *Saga {
+	return &Saga{ctx: ctx, options: options}
+}
+
+// AddCompensation registers an activity to run with the given args when the saga is compensated.
+// The activity is executed with ExecuteActivity and can be a function or the name of a registered activity.
+func (s *Saga) AddCompensation(activity interface{}, args ...interface{}) {
+	s.AddCompensationFunc(func(ctx Context) error {
+		return ExecuteActivity(ctx, activity, args...).Get(ctx, nil)
+	})
+}
+
+// AddCompensationFunc registers a function to run when the saga is compensated. It can be used for compensations
+// that are not a single activity, for example a child workflow or a local activity.
+func (s *Saga) AddCompensationFunc(fn func(ctx Context) error) {
+	s.compensations = append(s.compensations, fn)
+}
+
+// Compensate runs the registered compensations according to the SagaOptions and returns a *CompensationError
+// when any of them fails. Compensations are only run once, later calls return nil.
+func (s *Saga) Compensate() error {
+	if s.compensated {
+		return nil
+	}
+	s.compensated = true
+
+	ctx, _ := NewDisconnectedContext(s.ctx)
+	var errs []error
+	if s.options.ParallelCompensation {
+		errs = s.compensateParallel(ctx)
+	} else {
+		errs = s.compensateSequential(ctx)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &CompensationError{Errors: errs}
+}
+
+func (s *Saga) compensateSequential(ctx Context) []error {
+	var errs []error
+	for i := len(s.compensations) - 1; i >= 0; i-- {
+		if err := s.compensations[i](ctx); err != nil {
+			errs = append(errs, err)
+			if !s.options.ContinueWithError {
+				break
+			}
+		}
+	}
+	return errs
+}
+
+func (s *Saga) compensateParallel(ctx Context) []error {
+	var errs []error
+	pending := len(s.compensations)
+	for i := len(s.compensations) - 1; i >= 0; i-- {
+		compensation := s.compensations[i]
+		Go(ctx, func(ctx Context) {
+			if err := compensation(ctx); err != nil {
+				errs = append(errs, err)
+			}
+			pending--
+		})
+	}
+	_ = Await(ctx, func() bool { return pending == 0 })
+	return errs
+}
+
+// Error from error interface
+func (e *CompensationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("saga compensation failed: %v", strings.Join(msgs, "; "))
+}
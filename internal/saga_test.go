@@ -0,0 +1,190 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type SagaTestSuite struct {
+	*require.Assertions
+	suite.Suite
+	WorkflowTestSuite
+
+	sync.Mutex
+	compensated []string
+}
+
+func TestSagaTestSuite(t *testing.T) {
+	suite.Run(t, new(SagaTestSuite))
+}
+
+func (s *SagaTestSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	s.compensated = nil
+}
+
+func (s *SagaTestSuite) newTestWorkflowEnvironment() *TestWorkflowEnvironment {
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterActivityWithOptions(func(ctx context.Context, step string) error {
+		s.Lock()
+		defer s.Unlock()
+		s.compensated = append(s.compensated, step)
+		if step == "fail" {
+			return errors.New("compensation failed")
+		}
+		return nil
+	}, RegisterActivityOptions{Name: "compensate"})
+	return env
+}
+
+func (s *SagaTestSuite) withActivityOptions(ctx Context) Context {
+	return WithActivityOptions(ctx, ActivityOptions{
+		ScheduleToStartTimeout: time.Minute,
+		StartToCloseTimeout:    time.Minute,
+	})
+}
+
+func (s *SagaTestSuite) TestCompensateSequential() {
+	workflowFn := func(ctx Context) error {
+		saga := NewSaga(s.withActivityOptions(ctx), SagaOptions{})
+		saga.AddCompensation("compensate", "first")
+		saga.AddCompensation("compensate", "second")
+		saga.AddCompensationFunc(func(ctx Context) error {
+			return ExecuteActivity(ctx, "compensate", "third").Get(ctx, nil)
+		})
+		if err := saga.Compensate(); err != nil {
+			return err
+		}
+		// compensations only run once
+		return saga.Compensate()
+	}
+
+	env := s.newTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	s.Equal([]string{"third", "second", "first"}, s.compensated)
+}
+
+func (s *SagaTestSuite) TestCompensateSequential_StopOnError() {
+	workflowFn := func(ctx Context) error {
+		saga := NewSaga(s.withActivityOptions(ctx), SagaOptions{})
+		saga.AddCompensation("compensate", "first")
+		saga.AddCompensation("compensate", "fail")
+		saga.AddCompensation("compensate", "third")
+		return saga.Compensate()
+	}
+
+	env := s.newTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.Error(env.GetWorkflowError())
+	s.Contains(env.GetWorkflowError().Error(), "saga compensation failed: compensation failed")
+	s.Equal([]string{"third", "fail"}, s.compensated)
+}
+
+func (s *SagaTestSuite) TestCompensateSequential_ContinueWithError() {
+	workflowFn := func(ctx Context) (int, error) {
+		saga := NewSaga(s.withActivityOptions(ctx), SagaOptions{ContinueWithError: true})
+		saga.AddCompensation("compensate", "fail")
+		saga.AddCompensation("compensate", "second")
+		saga.AddCompensation("compensate", "fail")
+		err := saga.Compensate()
+		var compensationErr *CompensationError
+		if !errors.As(err, &compensationErr) {
+			return 0, errors.New("expected CompensationError")
+		}
+		return len(compensationErr.Errors), nil
+	}
+
+	env := s.newTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var failed int
+	s.NoError(env.GetWorkflowResult(&failed))
+	s.Equal(2, failed)
+	s.Equal([]string{"fail", "second", "fail"}, s.compensated)
+}
+
+func (s *SagaTestSuite) TestCompensateParallel() {
+	workflowFn := func(ctx Context) (time.Duration, error) {
+		saga := NewSaga(s.withActivityOptions(ctx), SagaOptions{ParallelCompensation: true})
+		for i := 0; i < 3; i++ {
+			saga.AddCompensationFunc(func(ctx Context) error {
+				return Sleep(ctx, time.Minute)
+			})
+		}
+		saga.AddCompensation("compensate", "fail")
+		start := Now(ctx)
+		err := saga.Compensate()
+		if err == nil {
+			return 0, errors.New("expected compensation error")
+		}
+		return Now(ctx).Sub(start), nil
+	}
+
+	env := s.newTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var elapsed time.Duration
+	s.NoError(env.GetWorkflowResult(&elapsed))
+	s.Equal(time.Minute, elapsed)
+	s.Equal([]string{"fail"}, s.compensated)
+}
+
+func (s *SagaTestSuite) TestCompensateAfterCancellation() {
+	workflowFn := func(ctx Context) (err error) {
+		ctx = s.withActivityOptions(ctx)
+		saga := NewSaga(ctx, SagaOptions{})
+		defer func() {
+			if err != nil {
+				_ = saga.Compensate()
+			}
+		}()
+
+		saga.AddCompensation("compensate", "first")
+		return Sleep(ctx, time.Hour)
+	}
+
+	env := s.newTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflowFn)
+	env.RegisterDelayedCallback(func() {
+		env.CancelWorkflow()
+	}, time.Minute)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.IsType(&CanceledError{}, env.GetWorkflowError())
+	s.Equal([]string{"first"}, s.compensated)
+}
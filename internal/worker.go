@@ -194,10 +194,20 @@ type (
 		// default: 1000
 		MaxConcurrentSessionExecutionSize int
 
+		// Optional: Isolates the worker registry from the global registry.
+		// When set, workflows and activities registered through workflow.Register and activity.Register are
+		// ignored by the worker, only the ones registered with the worker itself are executed.
+		// default: false
+		DisableGlobalRegistry bool
+
 		// Optional: Specifies factories used to instantiate workflow interceptor chain
 		// The chain is instantiated per each replay of a workflow execution
 		WorkflowInterceptorChainFactories []WorkflowInterceptorFactory
 
+		// Optional: Specifies factories used to instantiate activity interceptor chain
+		// The chain is instantiated per each activity execution, including local activities
+		ActivityInterceptorChainFactories []ActivityInterceptorFactory
+
 		// Optional: Sets ContextPropagators that allows users to control the context information passed through a workflow
 		// default: no ContextPropagators
 		ContextPropagators []ContextPropagator
@@ -22,12 +22,16 @@
 package internal
 
 import (
+	crand "crypto/rand"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"math/rand"
 	"reflect"
 	"strings"
 	"time"
 
+	"github.com/pborman/uuid"
 	"github.com/uber-go/tally"
 	s "go.uber.org/cadence/.gen/go/shared"
 	"go.uber.org/cadence/internal/common"
@@ -92,6 +96,34 @@ type (
 		Wait(ctx Context)
 	}
 
+	// Mutex must be used instead of native go sync.Mutex by workflow code to protect state shared by
+	// coroutines across blocking calls. Use workflow.NewMutex(ctx) method to create a new Mutex instance.
+	Mutex interface {
+		// Lock blocks until the mutex is acquired. Coroutines acquire the mutex in the order they called Lock.
+		// Returns CanceledError if the ctx is canceled before the mutex is acquired.
+		Lock(ctx Context) error
+		// TryLock acquires the mutex without blocking and returns whether it succeeded.
+		TryLock(ctx Context) bool
+		// Unlock releases the mutex. It panics if the mutex is not locked.
+		Unlock()
+		// IsLocked returns whether the mutex is held.
+		IsLocked() bool
+	}
+
+	// Semaphore must be used instead of native go semaphores by workflow code to limit the number of coroutines
+	// doing some work concurrently. Use workflow.NewSemaphore(ctx, size) method to create a new Semaphore instance.
+	Semaphore interface {
+		// Acquire blocks until the weight of n is acquired. Coroutines acquire the weight in the order they
+		// called Acquire. Returns CanceledError if the ctx is canceled before the weight is acquired, and an
+		// error if n is not positive or exceeds the size of the semaphore.
+		Acquire(ctx Context, n int64) error
+		// TryAcquire acquires the weight of n without blocking and returns whether it succeeded.
+		// It panics if n is not positive.
+		TryAcquire(ctx Context, n int64) bool
+		// Release releases the weight of n. It panics if n is not positive or more than held is released.
+		Release(n int64)
+	}
+
 	// Future represents the result of an asynchronous computation.
 	Future interface {
 		// Get blocks until the future is ready. When ready it either returns non nil error or assigns result value to
@@ -183,8 +215,13 @@ type (
 		// WaitForCancellation - Whether to wait for cancelled child workflow to be ended (child workflow can be ended
 		// as: completed/failed/timedout/terminated/canceled)
 		// Optional: default false
+		// Deprecated: use CancellationType instead.
 		WaitForCancellation bool
 
+		// CancellationType - What happens to the child workflow when the context is cancelled.
+		// Optional: default CancellationTypeUnspecified, which waits for the cancelled child workflow to be ended.
+		CancellationType CancellationType
+
 		// WorkflowIDReusePolicy - Whether server allow reuse of workflow ID, can be useful
 		// for dedup logic if set to WorkflowIdReusePolicyRejectDuplicate
 		WorkflowIDReusePolicy WorkflowIDReusePolicy
@@ -222,8 +259,65 @@ type (
 		// Default is Terminate (if onboarded to this feature)
 		ParentClosePolicy ParentClosePolicy
 	}
+
+	// ContinueAsNewOptions stores the options of the run started by NewContinueAsNewErrorWithOptions.
+	ContinueAsNewOptions struct {
+		// TaskList - The task list of the next run.
+		// Optional: the task list of the workflow context is used if this is not provided.
+		TaskList string
+
+		// ExecutionStartToCloseTimeout - The end to end timeout of the next run.
+		// Optional: the execution timeout of the workflow context is used if this is not provided.
+		ExecutionStartToCloseTimeout time.Duration
+
+		// DecisionTaskStartToCloseTimeout - The decision task timeout of the next run.
+		// Optional: the decision task timeout of the workflow context is used if this is not provided.
+		DecisionTaskStartToCloseTimeout time.Duration
+
+		// RetryPolicy specify how to retry the next run if error happens.
+		// Optional: default is no retry
+		RetryPolicy *RetryPolicy
+
+		// Memo - Optional non-indexed info of the next run. It replaces the memo of the current run, use
+		// NewContinueAsNewErrorWithCarryOver to keep it.
+		Memo map[string]interface{}
+
+		// SearchAttributes - Optional indexed info of the next run. They replace the search attributes of the current
+		// run, use NewContinueAsNewErrorWithCarryOver to keep them.
+		SearchAttributes map[string]interface{}
+	}
 )
 
+// CancellationType specifies what happens when the context of an activity or child workflow is cancelled.
+type CancellationType int
+
+const (
+	// CancellationTypeUnspecified keeps the behavior selected by the WaitForCancellation option. For activities it
+	// is CancellationTypeWaitCancellationCompleted when WaitForCancellation is set and CancellationTypeTryCancel
+	// otherwise. Child workflows always wait for the cancellation to complete, as in earlier releases.
+	CancellationTypeUnspecified CancellationType = iota
+	// CancellationTypeTryCancel requests cancellation and fails the future with CanceledError immediately, without
+	// waiting for the activity or child workflow to handle the request.
+	CancellationTypeTryCancel
+	// CancellationTypeWaitCancellationCompleted requests cancellation and resolves the future only once the activity
+	// or child workflow is closed, with whatever result it ends with.
+	CancellationTypeWaitCancellationCompleted
+	// CancellationTypeAbandon fails the future with CanceledError immediately without requesting cancellation, the
+	// activity or child workflow keeps running and its result is ignored.
+	CancellationTypeAbandon
+)
+
+// resolve replaces CancellationTypeUnspecified with the cancellation type selected by waitForCancellation.
+func (t CancellationType) resolve(waitForCancellation bool) CancellationType {
+	if t != CancellationTypeUnspecified {
+		return t
+	}
+	if waitForCancellation {
+		return CancellationTypeWaitCancellationCompleted
+	}
+	return CancellationTypeTryCancel
+}
+
 // RegisterWorkflowOptions consists of options for registering a workflow
 type RegisterWorkflowOptions struct {
 	Name                          string
@@ -287,6 +381,37 @@ func Await(ctx Context, condition func() bool) error {
 	return nil
 }
 
+// AwaitWithTimeout blocks the calling thread until condition() returns true or the timeout expires.
+// Returns ok equal to false if the timeout expired, and CanceledError if the ctx is canceled.
+// The timeout is backed by a durable timer which is canceled once the condition becomes true.
+func AwaitWithTimeout(ctx Context, timeout time.Duration, condition func() bool) (ok bool, err error) {
+	if condition() {
+		return true, nil
+	}
+	state := getState(ctx)
+	defer state.unblocked()
+
+	timerCtx, cancelTimer := WithCancel(ctx)
+	defer cancelTimer()
+	timer := NewTimer(timerCtx, timeout)
+	for !condition() {
+		doneCh := ctx.Done()
+		if doneCh != nil {
+			if _, more := doneCh.ReceiveAsyncWithMoreFlag(nil); !more {
+				return false, NewCanceledError("AwaitWithTimeout context cancelled")
+			}
+		}
+		if timer.IsReady() {
+			if err := timer.Get(ctx, nil); err != nil {
+				return false, err
+			}
+			return false, nil
+		}
+		state.yield("AwaitWithTimeout")
+	}
+	return true, nil
+}
+
 // NewChannel create new Channel instance
 func NewChannel(ctx Context) Channel {
 	state := getState(ctx)
@@ -333,6 +458,29 @@ func NewWaitGroup(ctx Context) WaitGroup {
 	return &waitGroupImpl{future: f, settable: s}
 }
 
+// NewMutex creates a new Mutex instance.
+func NewMutex(ctx Context) Mutex {
+	state := getState(ctx)
+	state.dispatcher.mutexSequence++
+	return &mutexImpl{semaphore: &semaphoreImpl{
+		name: fmt.Sprintf("mutex-%v", state.dispatcher.mutexSequence),
+		size: 1,
+	}}
+}
+
+// NewSemaphore creates a new Semaphore instance with the given total weight. It panics if size is not positive.
+func NewSemaphore(ctx Context, size int64) Semaphore {
+	if size <= 0 {
+		panic(fmt.Sprintf("NewSemaphore: size %v must be positive", size))
+	}
+	state := getState(ctx)
+	state.dispatcher.semaphoreSequence++
+	return &semaphoreImpl{
+		name: fmt.Sprintf("semaphore-%v", state.dispatcher.semaphoreSequence),
+		size: size,
+	}
+}
+
 // Go creates a new coroutine. It has similar semantic to goroutine in a context of the workflow.
 func Go(ctx Context, f func(ctx Context)) {
 	state := getState(ctx)
@@ -671,11 +819,15 @@ func (wc *workflowEnvironmentInterceptor) ExecuteChildWorkflow(ctx Context, chil
 	}
 
 	var childWorkflowExecution *WorkflowExecution
+	var cancelAfterStarted bool
 
 	ctxDone, cancellable := ctx.Done().(*channelImpl)
 	cancellationCallback := &receiveCallback{}
 	err = getWorkflowEnvironment(ctx).ExecuteChildWorkflow(params, func(r []byte, e error) {
-		mainSettable.Set(r, e)
+		if !mainFuture.IsReady() {
+			// future is already failed if the child workflow was cancelled without waiting for it
+			mainSettable.Set(r, e)
+		}
 		if cancellable {
 			// future is done, we don't need cancellation anymore
 			ctxDone.removeReceiveCallback(cancellationCallback)
@@ -683,6 +835,9 @@ func (wc *workflowEnvironmentInterceptor) ExecuteChildWorkflow(ctx Context, chil
 	}, func(r WorkflowExecution, e error) {
 		if e == nil {
 			childWorkflowExecution = &r
+			if cancelAfterStarted {
+				getWorkflowEnvironment(ctx).RequestCancelChildWorkflow(*options.domain, childWorkflowExecution.ID)
+			}
 		}
 		executionSettable.Set(r, e)
 	})
@@ -695,9 +850,24 @@ func (wc *workflowEnvironmentInterceptor) ExecuteChildWorkflow(ctx Context, chil
 
 	if cancellable {
 		cancellationCallback.fn = func(v interface{}, more bool) bool {
-			if ctx.Err() == ErrCanceled && childWorkflowExecution != nil && !mainFuture.IsReady() {
-				// child workflow started, and ctx cancelled
-				getWorkflowEnvironment(ctx).RequestCancelChildWorkflow(*options.domain, childWorkflowExecution.ID)
+			if ctx.Err() != ErrCanceled || mainFuture.IsReady() {
+				return false
+			}
+			switch options.cancellationType {
+			case CancellationTypeTryCancel:
+				if childWorkflowExecution != nil {
+					getWorkflowEnvironment(ctx).RequestCancelChildWorkflow(*options.domain, childWorkflowExecution.ID)
+				} else {
+					cancelAfterStarted = true
+				}
+				mainSettable.Set(nil, ErrCanceled)
+			case CancellationTypeAbandon:
+				mainSettable.Set(nil, ErrCanceled)
+			default:
+				if childWorkflowExecution != nil {
+					// child workflow started, and ctx cancelled
+					getWorkflowEnvironment(ctx).RequestCancelChildWorkflow(*options.domain, childWorkflowExecution.ID)
+				}
 			}
 			return false
 		}
@@ -738,6 +908,55 @@ type WorkflowInfo struct {
 	Memo                                *s.Memo             // Value can be decoded using data converter (DefaultDataConverter, or custom one if set).
 	SearchAttributes                    *s.SearchAttributes // Value can be decoded using DefaultDataConverter.
 	BinaryChecksum                      *string
+	// HistoryLength is the number of history events processed so far, including the ones that triggered the
+	// current decision. It is the same during replay, so it can be used to decide when to continue as new.
+	// It is always 0 in the TestWorkflowEnvironment as it does not produce history.
+	HistoryLength int64
+	dataConverter DataConverter
+	historySize   int64
+}
+
+// GetMemo returns the memo of the workflow by key. Values can be decoded into the type they were set with.
+// Example:
+//  var owner string
+//  if value, ok := workflow.GetInfo(ctx).GetMemo()["owner"]; ok {
+//    err := value.Get(&owner)
+//  }
+func (wInfo *WorkflowInfo) GetMemo() map[string]Value {
+	result := make(map[string]Value)
+	if wInfo.Memo == nil {
+		return result
+	}
+	dc := wInfo.dataConverter
+	if dc == nil {
+		dc = getDefaultDataConverter()
+	}
+	for k, v := range wInfo.Memo.Fields {
+		result[k] = newEncodedValue(v, dc)
+	}
+	return result
+}
+
+// GetHistorySize returns the total size in bytes of the history events counted in HistoryLength, as encoded by
+// thrift. Like HistoryLength it is the same during replay.
+// It is always 0 in the TestWorkflowEnvironment as it does not produce history.
+func (wInfo *WorkflowInfo) GetHistorySize() int64 {
+	return wInfo.historySize
+}
+
+// GetSearchAttributes returns the current search attributes of the workflow by key, including the ones set by
+// UpsertSearchAttributes. Values can be decoded into the type they were set with. Search attributes are always
+// JSON encoded, so unlike GetMemo the values are decoded with the default DataConverter rather than the one of the
+// workflow.
+func (wInfo *WorkflowInfo) GetSearchAttributes() map[string]Value {
+	result := make(map[string]Value)
+	if wInfo.SearchAttributes == nil {
+		return result
+	}
+	for k, v := range wInfo.SearchAttributes.IndexedFields {
+		result[k] = newEncodedValue(v, getDefaultDataConverter())
+	}
+	return result
 }
 
 func (wInfo *WorkflowInfo) GetBinaryChecksum() string {
@@ -999,6 +1218,7 @@ func WithChildWorkflowOptions(ctx Context, cwo ChildWorkflowOptions) Context {
 	wfOptions.executionStartToCloseTimeoutSeconds = common.Int32Ptr(common.Int32Ceil(cwo.ExecutionStartToCloseTimeout.Seconds()))
 	wfOptions.taskStartToCloseTimeoutSeconds = common.Int32Ptr(common.Int32Ceil(cwo.TaskStartToCloseTimeout.Seconds()))
 	wfOptions.waitForCancellation = cwo.WaitForCancellation
+	wfOptions.cancellationType = cwo.CancellationType
 	wfOptions.workflowIDReusePolicy = cwo.WorkflowIDReusePolicy
 	wfOptions.retryPolicy = convertRetryPolicy(cwo.RetryPolicy)
 	wfOptions.cronSchedule = cwo.CronSchedule
@@ -1072,9 +1292,27 @@ func GetSignalChannel(ctx Context, signalName string) Channel {
 }
 
 func (wc *workflowEnvironmentInterceptor) GetSignalChannel(ctx Context, signalName string) Channel {
+	if wc.signalListeners == nil {
+		wc.signalListeners = make(map[string]bool)
+	}
+	wc.signalListeners[signalName] = true
 	return getWorkflowEnvOptions(ctx).getSignalChannel(ctx, signalName)
 }
 
+// SetSignalHandler sets the handler invoked for every signal named signalName. The handler must be a function taking
+// workflow.Context and optionally one parameter the signal payload is decoded into. Handlers run one at a time in
+// the order the signals were received. See workflow.SetSignalHandler for more details.
+func SetSignalHandler(ctx Context, signalName string, handler interface{}) error {
+	i := getWorkflowInterceptor(ctx)
+	return i.SetSignalHandler(ctx, signalName, handler)
+}
+
+// GetUnhandledSignalNames returns the sorted names of the signals received but not yet consumed by the workflow.
+func GetUnhandledSignalNames(ctx Context) []string {
+	i := getWorkflowInterceptor(ctx)
+	return i.GetUnhandledSignalNames(ctx)
+}
+
 func newEncodedValue(value []byte, dc DataConverter) Value {
 	if dc == nil {
 		dc = getDefaultDataConverter()
@@ -1181,6 +1419,49 @@ func (wc *workflowEnvironmentInterceptor) MutableSideEffect(ctx Context, id stri
 	return wc.env.MutableSideEffect(id, wrapperFunc, equals)
 }
 
+// NewRandom returns a pseudo random number generator that is safe to use in workflow code.
+// All generators of a workflow run are derived from a single seed that is recorded through SideEffect
+// on the first call, so they produce identical sequences on replay without recording a marker per draw.
+// Each call returns a new generator with its own sequence. The returned generator is not safe for use
+// outside of the workflow that created it.
+func NewRandom(ctx Context) *rand.Rand {
+	return rand.New(rand.NewSource(getWorkflowRandom(ctx).Int63()))
+}
+
+// NewUUID returns a random (version 4) UUID string that is safe to use in workflow code.
+// It is derived from the same recorded seed as NewRandom and returns identical values on replay.
+func NewUUID(ctx Context) string {
+	b := make([]byte, 16)
+	getWorkflowRandom(ctx).Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return uuid.UUID(b).String()
+}
+
+// getWorkflowRandom returns the generator of the workflow run that all NewRandom and NewUUID calls draw from.
+func getWorkflowRandom(ctx Context) *rand.Rand {
+	wc := getEnvInterceptor(ctx)
+	if wc.random == nil {
+		var seed int64
+		encodedSeed := SideEffect(ctx, func(ctx Context) interface{} {
+			return newRandomSeed()
+		})
+		if err := encodedSeed.Get(&seed); err != nil {
+			panic(err)
+		}
+		wc.random = rand.New(rand.NewSource(seed))
+	}
+	return wc.random
+}
+
+func newRandomSeed() int64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		return time.Now().UnixNano()
+	}
+	return int64(binary.LittleEndian.Uint64(b[:]))
+}
+
 // DefaultVersion is a version returned by GetVersion for code that wasn't versioned before
 const DefaultVersion Version = -1
 
@@ -1303,6 +1584,15 @@ func (wc *workflowEnvironmentInterceptor) SetQueryHandler(ctx Context, queryType
 	return setQueryHandler(ctx, queryType, handler)
 }
 
+// SetUpdateHandler sets the handler of the updates named updateName sent by Client.UpdateWorkflow.
+// The handler must be a function taking workflow.Context and any number of serializable parameters and returning
+// either error or a serializable result and error. Each update is handled in its own coroutine.
+// See workflow.SetUpdateHandler for more details.
+func SetUpdateHandler(ctx Context, updateName string, handler interface{}, options UpdateHandlerOptions) error {
+	i := getWorkflowInterceptor(ctx)
+	return i.SetUpdateHandler(ctx, updateName, handler, options)
+}
+
 // IsReplaying returns whether the current workflow code is replaying.
 //
 // Warning! Never make decisions, like schedule activity/childWorkflow/timer or send/wait on future/channel, based on
@@ -1372,7 +1662,7 @@ func WithActivityOptions(ctx Context, options ActivityOptions) Context {
 	eap.StartToCloseTimeoutSeconds = common.Int32Ceil(options.StartToCloseTimeout.Seconds())
 	eap.ScheduleToStartTimeoutSeconds = common.Int32Ceil(options.ScheduleToStartTimeout.Seconds())
 	eap.HeartbeatTimeoutSeconds = common.Int32Ceil(options.HeartbeatTimeout.Seconds())
-	eap.WaitForCancellation = options.WaitForCancellation
+	eap.CancellationType = options.CancellationType.resolve(options.WaitForCancellation)
 	eap.ActivityID = common.StringPtr(options.ActivityID)
 	eap.RetryPolicy = convertRetryPolicy(options.RetryPolicy)
 	return ctx1
@@ -1436,7 +1726,14 @@ func WithHeartbeatTimeout(ctx Context, d time.Duration) Context {
 // WithWaitForCancellation adds wait for the cacellation to the copy of the context.
 func WithWaitForCancellation(ctx Context, wait bool) Context {
 	ctx1 := setActivityParametersIfNotExist(ctx)
-	getActivityOptions(ctx1).WaitForCancellation = wait
+	getActivityOptions(ctx1).CancellationType = CancellationTypeUnspecified.resolve(wait)
+	return ctx1
+}
+
+// WithCancellationType adds the activity cancellation type to the copy of the context.
+func WithCancellationType(ctx Context, cancellationType CancellationType) Context {
+	ctx1 := setActivityParametersIfNotExist(ctx)
+	getActivityOptions(ctx1).CancellationType = cancellationType.resolve(false)
 	return ctx1
 }
 
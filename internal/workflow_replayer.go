@@ -45,9 +45,24 @@ type WorkflowReplayer struct {
 	registry *registry
 }
 
+// WorkflowReplayerOptions are options used by NewWorkflowReplayerWithOptions
+type WorkflowReplayerOptions struct {
+	// Optional: Isolates the replayer registry from the global registry.
+	// When set, workflows registered through workflow.Register are ignored by the replayer.
+	// default: false
+	DisableGlobalRegistry bool
+}
+
 // NewWorkflowReplayer creates an instance of the WorkflowReplayer
 func NewWorkflowReplayer() *WorkflowReplayer {
-	return &WorkflowReplayer{registry: newRegistry()}
+	return NewWorkflowReplayerWithOptions(WorkflowReplayerOptions{})
+}
+
+// NewWorkflowReplayerWithOptions creates an instance of the WorkflowReplayer with the provided options
+func NewWorkflowReplayerWithOptions(options WorkflowReplayerOptions) *WorkflowReplayer {
+	return &WorkflowReplayer{
+		registry: newRegistryWithOptions(registryOptions{disableGlobalRegistry: options.DisableGlobalRegistry}),
+	}
 }
 
 // RegisterWorkflow registers workflow function to replay
@@ -60,6 +75,11 @@ func (r *WorkflowReplayer) RegisterWorkflowWithOptions(w interface{}, options Re
 	r.registry.RegisterWorkflowWithOptions(w, options)
 }
 
+// GetRegisteredWorkflows returns the workflows the replayer is able to replay
+func (r *WorkflowReplayer) GetRegisteredWorkflows() []RegisteredWorkflowInfo {
+	return r.registry.listWorkflows()
+}
+
 // ReplayWorkflowHistory executes a single decision task for the given history.
 // Use for testing backwards compatibility of code changes and troubleshooting workflows in a debugger.
 // The logger is an optional parameter. Defaults to the noop logger.
@@ -45,10 +45,11 @@ type (
 
 	// WorkflowTestSuite is the test suite to run unit tests for workflow/activity.
 	WorkflowTestSuite struct {
-		logger   *zap.Logger
-		scope    tally.Scope
-		ctxProps []ContextPropagator
-		header   *shared.Header
+		logger                *zap.Logger
+		scope                 tally.Scope
+		ctxProps              []ContextPropagator
+		header                *shared.Header
+		disableGlobalRegistry bool
 	}
 
 	// TestWorkflowEnvironment is the environment that you use to test workflow
@@ -152,6 +153,13 @@ func (s *WorkflowTestSuite) SetHeader(header *shared.Header) {
 	s.header = header
 }
 
+// SetDisableGlobalRegistry isolates the registry of environments created by this WorkflowTestSuite from the global
+// registry. When set, workflows and activities registered through workflow.Register and activity.Register are not
+// visible to the environments, they must be registered with each environment instead.
+func (s *WorkflowTestSuite) SetDisableGlobalRegistry(disable bool) {
+	s.disableGlobalRegistry = disable
+}
+
 // RegisterActivity registers activity implementation with TestWorkflowEnvironment
 func (t *TestActivityEnvironment) RegisterActivity(a interface{}) {
 	t.impl.RegisterActivity(a)
@@ -223,6 +231,16 @@ func (t *TestWorkflowEnvironment) RegisterActivityWithOptions(a interface{}, opt
 	t.impl.RegisterActivityWithOptions(a, options)
 }
 
+// GetRegisteredWorkflows returns the workflows registered with TestWorkflowEnvironment
+func (t *TestWorkflowEnvironment) GetRegisteredWorkflows() []RegisteredWorkflowInfo {
+	return t.impl.registry.listWorkflows()
+}
+
+// GetRegisteredActivities returns the activities registered with TestWorkflowEnvironment
+func (t *TestWorkflowEnvironment) GetRegisteredActivities() []RegisteredActivityInfo {
+	return t.impl.registry.listActivities()
+}
+
 // SetStartTime sets the start time of the workflow. This is optional, default start time will be the wall clock time when
 // workflow starts. Start time is the workflow.Now(ctx) time at the beginning of the workflow.
 func (t *TestWorkflowEnvironment) SetStartTime(startTime time.Time) {
@@ -621,6 +639,19 @@ func (t *TestWorkflowEnvironment) QueryWorkflow(queryType string, args ...interf
 	return t.impl.queryWorkflow(queryType, args...)
 }
 
+// UpdateWorkflow sends update to the currently running test workflow. The updateID identifies the update in
+// GetUpdateResult and deduplicates it like in Client.UpdateWorkflow.
+func (t *TestWorkflowEnvironment) UpdateWorkflow(updateName, updateID string, args ...interface{}) {
+	t.impl.updateWorkflow(updateName, updateID, args...)
+}
+
+// GetUpdateResult returns the outcome of an update sent by UpdateWorkflow. The error is UpdateRejectedError if the
+// update was rejected or the error returned by the update handler. It returns an error if the update has not
+// completed yet.
+func (t *TestWorkflowEnvironment) GetUpdateResult(updateID string) (Value, error) {
+	return t.impl.getUpdateResult(updateID)
+}
+
 // RegisterDelayedCallback creates a new timer with specified delayDuration using workflow clock (not wall clock). When
 // the timer fires, the callback will be called. By default, this test suite uses mock clock which automatically move
 // forward to fire next timer when workflow is blocked. Use this API to make some event (like activity completion,
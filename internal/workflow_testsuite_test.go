@@ -88,6 +88,45 @@ func TestUnregisteredActivity(t *testing.T) {
 	require.True(t, strings.HasPrefix(ee.Error(), "unable to find activityType=unregistered"), ee.Error())
 }
 
+func testGlobalOnlyActivity(ctx context.Context) (string, error) {
+	return "global", nil
+}
+
+func TestDisableGlobalRegistry(t *testing.T) {
+	t.Parallel()
+	getGlobalRegistry().RegisterActivityWithOptions(
+		testGlobalOnlyActivity,
+		RegisterActivityOptions{Name: "globalOnlyActivity", DisableAlreadyRegisteredCheck: true},
+	)
+	workflow := func(ctx Context) (string, error) {
+		ctx = WithActivityOptions(ctx, ActivityOptions{
+			ScheduleToStartTimeout: time.Minute,
+			StartToCloseTimeout:    time.Minute,
+		})
+		var result string
+		err := ExecuteActivity(ctx, "globalOnlyActivity").Get(ctx, &result)
+		return result, err
+	}
+
+	testSuite := &WorkflowTestSuite{}
+	env := testSuite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflow)
+	env.ExecuteWorkflow(workflow)
+	require.NoError(t, env.GetWorkflowError())
+
+	testSuite.SetDisableGlobalRegistry(true)
+	env = testSuite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflow)
+	for _, a := range env.GetRegisteredActivities() {
+		require.NotEqual(t, "globalOnlyActivity", a.ActivityType.Name)
+	}
+	require.Len(t, env.GetRegisteredWorkflows(), 1)
+	env.ExecuteWorkflow(workflow)
+	ee := env.GetWorkflowError()
+	require.Error(t, ee)
+	require.True(t, strings.HasPrefix(ee.Error(), "unable to find activityType=globalOnlyActivity"), ee.Error())
+}
+
 func TestNoExplicitRegistrationRequired(t *testing.T) {
 	testSuite := &WorkflowTestSuite{}
 	env := testSuite.NewTestWorkflowEnvironment()
@@ -39,6 +39,29 @@ type Client struct {
 	mock.Mock
 }
 
+// BatchWorkflow provides a mock function with given fields: ctx, request
+func (_m *Client) BatchWorkflow(ctx context.Context, request *client.BatchWorkflowRequest) (*client.BatchWorkflowResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *client.BatchWorkflowResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *client.BatchWorkflowRequest) *client.BatchWorkflowResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*client.BatchWorkflowResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *client.BatchWorkflowRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CancelWorkflow provides a mock function with given fields: ctx, workflowID, runID
 func (_m *Client) CancelWorkflow(ctx context.Context, workflowID string, runID string) error {
 	ret := _m.Called(ctx, workflowID, runID)
@@ -408,6 +431,52 @@ func (_m *Client) RecordActivityHeartbeatByID(ctx context.Context, domain string
 	return r0
 }
 
+// ResetWorkflow provides a mock function with given fields: ctx, request
+func (_m *Client) ResetWorkflow(ctx context.Context, request *client.ResetWorkflowRequest) (*workflow.Execution, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *workflow.Execution
+	if rf, ok := ret.Get(0).(func(context.Context, *client.ResetWorkflowRequest) *workflow.Execution); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*workflow.Execution)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *client.ResetWorkflowRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResetWorkflows provides a mock function with given fields: ctx, request
+func (_m *Client) ResetWorkflows(ctx context.Context, request *client.ResetWorkflowsRequest) (*client.BatchWorkflowResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *client.BatchWorkflowResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *client.ResetWorkflowsRequest) *client.BatchWorkflowResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*client.BatchWorkflowResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *client.ResetWorkflowsRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ScanWorkflow provides a mock function with given fields: ctx, request
 func (_m *Client) ScanWorkflow(ctx context.Context, request *shared.ListWorkflowExecutionsRequest) (*shared.ListWorkflowExecutionsResponse, error) {
 	ret := _m.Called(ctx, request)
@@ -510,3 +579,52 @@ func (_m *Client) TerminateWorkflow(ctx context.Context, workflowID string, runI
 
 	return r0
 }
+
+// UpdateWorkflow provides a mock function with given fields: ctx, workflowID, runID, updateName, args
+func (_m *Client) UpdateWorkflow(ctx context.Context, workflowID string, runID string, updateName string, args ...interface{}) (encoded.Value, error) {
+	var _ca []interface{}
+	_ca = append(_ca, ctx, workflowID, runID, updateName)
+	_ca = append(_ca, args...)
+	ret := _m.Called(_ca...)
+
+	var r0 encoded.Value
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, ...interface{}) encoded.Value); ok {
+		r0 = rf(ctx, workflowID, runID, updateName, args...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(encoded.Value)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, ...interface{}) error); ok {
+		r1 = rf(ctx, workflowID, runID, updateName, args...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateWorkflowWithOptions provides a mock function with given fields: ctx, request
+func (_m *Client) UpdateWorkflowWithOptions(ctx context.Context, request *client.UpdateWorkflowWithOptionsRequest) (encoded.Value, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 encoded.Value
+	if rf, ok := ret.Get(0).(func(context.Context, *client.UpdateWorkflowWithOptionsRequest) encoded.Value); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(encoded.Value)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *client.UpdateWorkflowWithOptionsRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
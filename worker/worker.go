@@ -107,6 +107,14 @@ type (
 		// worker.RegisterActivityWithOptions(barActivity, RegisterActivityOptions{DisableAlreadyRegisteredCheck: true})
 		RegisterActivityWithOptions(a interface{}, options activity.RegisterOptions)
 
+		// GetRegisteredWorkflows returns the workflows the worker is able to execute sorted by type name.
+		// Unless Options.DisableGlobalRegistry is set, it includes the workflows registered through workflow.Register.
+		GetRegisteredWorkflows() []RegisteredWorkflowInfo
+
+		// GetRegisteredActivities returns the activities the worker is able to execute sorted by type name.
+		// Unless Options.DisableGlobalRegistry is set, it includes the activities registered through activity.Register.
+		GetRegisteredActivities() []RegisteredActivityInfo
+
 		// Start starts the worker in a non-blocking fashion
 		Start() error
 		// Run is a blocking start and cleans up resources when killed
@@ -131,6 +139,9 @@ type (
 		// RegisterWorkflowWithOptions registers workflow that is going to be replayed with user provided name
 		RegisterWorkflowWithOptions(w interface{}, options workflow.RegisterOptions)
 
+		// GetRegisteredWorkflows returns the workflows the replayer is able to replay sorted by type name.
+		GetRegisteredWorkflows() []RegisteredWorkflowInfo
+
 		// ReplayWorkflowHistory executes a single decision task for the given json history file.
 		// Use for testing the backwards compatibility of code changes and troubleshooting workflows in a debugger.
 		// The logger is an optional parameter. Defaults to the noop logger.
@@ -160,6 +171,15 @@ type (
 	// Options is used to configure a worker instance.
 	Options = internal.WorkerOptions
 
+	// ReplayerOptions is used to configure a WorkflowReplayer instance.
+	ReplayerOptions = internal.WorkflowReplayerOptions
+
+	// RegisteredWorkflowInfo describes a workflow registered with a worker.
+	RegisteredWorkflowInfo = internal.RegisteredWorkflowInfo
+
+	// RegisteredActivityInfo describes an activity registered with a worker.
+	RegisteredActivityInfo = internal.RegisteredActivityInfo
+
 	// NonDeterministicWorkflowPolicy is an enum for configuring how client's decision task handler deals with
 	// mismatched history events (presumably arising from non-deterministic workflow definitions).
 	NonDeterministicWorkflowPolicy = internal.NonDeterministicWorkflowPolicy
@@ -201,6 +221,11 @@ func NewWorkflowReplayer() WorkflowReplayer {
 	return internal.NewWorkflowReplayer()
 }
 
+// NewWorkflowReplayerWithOptions creates a WorkflowReplayer instance with the provided options.
+func NewWorkflowReplayerWithOptions(options ReplayerOptions) WorkflowReplayer {
+	return internal.NewWorkflowReplayerWithOptions(options)
+}
+
 // EnableVerboseLogging enable or disable verbose logging of internal Cadence library components.
 // Most customers don't need this feature, unless advised by the Cadence team member.
 // Also there is no guarantee that this API is not going to change.
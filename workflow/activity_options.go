@@ -105,6 +105,13 @@ func WithWaitForCancellation(ctx Context, wait bool) Context {
 	return internal.WithWaitForCancellation(ctx, wait)
 }
 
+// WithCancellationType makes a copy of the current context and update
+// the CancellationType field in its activity options. An empty activity
+// options will be created if it does not exist in the original context.
+func WithCancellationType(ctx Context, cancellationType CancellationType) Context {
+	return internal.WithCancellationType(ctx, cancellationType)
+}
+
 // WithRetryPolicy makes a copy of the current context and update
 // the RetryPolicy field in its activity options. An empty activity
 // options will be created if it does not exist in the original context.
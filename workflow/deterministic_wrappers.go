@@ -47,6 +47,14 @@ type (
 	// WaitGroup is used to wait for a collection of
 	// coroutines to finish
 	WaitGroup = internal.WaitGroup
+
+	// Mutex is used to protect state shared by coroutines across blocking calls.
+	// Use workflow.NewMutex(ctx) method to create a Mutex instance.
+	Mutex = internal.Mutex
+
+	// Semaphore is used to limit the number of coroutines doing some work concurrently.
+	// Use workflow.NewSemaphore(ctx, size) method to create a Semaphore instance.
+	Semaphore = internal.Semaphore
 )
 
 // Blocks the calling thread until condition() returns true.
@@ -62,6 +70,20 @@ func Await(ctx Context, condition func() bool) error {
 	return internal.Await(ctx, condition)
 }
 
+// AwaitWithTimeout blocks the calling thread until condition() returns true or the timeout expires.
+// Returns ok equal to false if the timeout expired, and CanceledError if the ctx is canceled.
+// The timeout is backed by a timer recorded in the workflow history, which is canceled as soon as the condition
+// becomes true, so the wait is replayed deterministically.
+// Do not mutate values or trigger side effects inside condition.
+// The following code is going to block until the captured count variable is set to 5 or for at most one hour.
+//
+// ok, err := workflow.AwaitWithTimeout(ctx, time.Hour, func() bool {
+//   return count == 5
+// })
+func AwaitWithTimeout(ctx Context, timeout time.Duration, condition func() bool) (ok bool, err error) {
+	return internal.AwaitWithTimeout(ctx, timeout, condition)
+}
+
 // NewChannel create new Channel instance
 func NewChannel(ctx Context) Channel {
 	return internal.NewChannel(ctx)
@@ -100,6 +122,73 @@ func NewWaitGroup(ctx Context) WaitGroup {
 	return internal.NewWaitGroup(ctx)
 }
 
+// NewMutex creates a new Mutex instance. Blocked Lock calls appear in the "__stack_trace" query result.
+// Example of protecting state updated by coroutines that call activities:
+//  mutex := workflow.NewMutex(ctx)
+//  workflow.Go(ctx, func(ctx workflow.Context) {
+//    if err := mutex.Lock(ctx); err != nil {
+//      return
+//    }
+//    defer mutex.Unlock()
+//    ...
+//  })
+func NewMutex(ctx Context) Mutex {
+	return internal.NewMutex(ctx)
+}
+
+// NewSemaphore creates a new Semaphore instance of the given total weight. Blocked Acquire calls appear in the
+// "__stack_trace" query result. It panics if size is not positive.
+func NewSemaphore(ctx Context, size int64) Semaphore {
+	return internal.NewSemaphore(ctx, size)
+}
+
+// AllOf returns a Future that becomes ready when all the futures are ready. Its Get returns the error of the first
+// failed future in the order the futures are passed, the values must be obtained from the futures themselves.
+func AllOf(ctx Context, futures ...Future) Future {
+	return internal.AllOf(ctx, futures...)
+}
+
+// AllOfOrFirstError returns a Future that becomes ready when all the futures complete successfully or as soon as
+// one of them fails. On the first failure cancel is called and the returned Future fails with that error.
+// Start the operations with a context created by workflow.WithCancel and pass its CancelFunc to cancel the ones
+// still running. The cancel parameter can be nil.
+// Example:
+//  ctx, cancel := workflow.WithCancel(ctx)
+//  var futures []workflow.Future
+//  for _, input := range inputs {
+//    futures = append(futures, workflow.ExecuteActivity(ctx, MyActivity, input))
+//  }
+//  err := workflow.AllOfOrFirstError(ctx, cancel, futures...).Get(ctx, nil)
+func AllOfOrFirstError(ctx Context, cancel CancelFunc, futures ...Future) Future {
+	return internal.AllOfOrFirstError(ctx, cancel, futures...)
+}
+
+// AnyOf returns a Future that becomes ready when any of the futures is ready. Its value is the index of the first
+// ready future, its result must be obtained from that future. When several futures are ready at the same time the
+// lowest index is returned.
+// Example:
+//  var index int
+//  _ = workflow.AnyOf(ctx, activityFuture, workflow.NewTimer(ctx, time.Hour)).Get(ctx, &index)
+func AnyOf(ctx Context, futures ...Future) Future {
+	return internal.AnyOf(ctx, futures...)
+}
+
+// ParallelMap calls fn for every element of the items slice, running at most concurrency calls at once. A
+// concurrency lower than one means no limit. The fn must be a function taking workflow.Context and a parameter of the
+// items element type and returning either error or a result and error. When resultsPtr is not nil it must point to
+// a slice the results are assigned to, in the order of items. On the first error no more calls are started, the
+// context passed to the running ones is canceled and the error is returned once they complete.
+// Example:
+//  var results []string
+//  err := workflow.ParallelMap(ctx, names, 10, func(ctx workflow.Context, name string) (string, error) {
+//    var greeting string
+//    err := workflow.ExecuteActivity(ctx, GreetActivity, name).Get(ctx, &greeting)
+//    return greeting, err
+//  }, &results)
+func ParallelMap(ctx Context, items interface{}, concurrency int, fn interface{}, resultsPtr interface{}) error {
+	return internal.ParallelMap(ctx, items, concurrency, fn, resultsPtr)
+}
+
 // Go creates a new coroutine. It has similar semantic to goroutine in a context of the workflow.
 func Go(ctx Context, f func(ctx Context)) {
 	internal.Go(ctx, f)
@@ -121,6 +121,27 @@ func NewContinueAsNewError(ctx Context, wfn interface{}, args ...interface{}) *C
 	return internal.NewContinueAsNewError(ctx, wfn, args...)
 }
 
+// NewContinueAsNewErrorWithOptions creates ContinueAsNewError instance like NewContinueAsNewError, with the task list,
+// timeouts, retry policy, memo and search attributes of the new execution taken from options. For example:
+//  return workflow.NewContinueAsNewErrorWithOptions(ctx, workflow.ContinueAsNewOptions{
+//    DecisionTaskStartToCloseTimeout: time.Minute,
+//    RetryPolicy:                     retryPolicy,
+//    Memo:                            map[string]interface{}{"Status": "resumed"},
+//  }, MyWorkflow, state)
+// Task list and timeouts that are not set in options are inherited from ctx. Memo and search attributes of options
+// replace the ones of the current execution, so the new execution starts without them if they are not set.
+// If the memo or search attributes of options cannot be serialized, the serialization error is returned instead.
+func NewContinueAsNewErrorWithOptions(ctx Context, options ContinueAsNewOptions, wfn interface{}, args ...interface{}) error {
+	return internal.NewContinueAsNewErrorWithOptions(ctx, options, wfn, args...)
+}
+
+// NewContinueAsNewErrorWithCarryOver creates ContinueAsNewError instance like NewContinueAsNewErrorWithOptions,
+// but the new execution keeps the memo and search attributes of the current execution, including the ones set
+// through UpsertSearchAttributes. Memo and search attributes of options are merged on top of them.
+func NewContinueAsNewErrorWithCarryOver(ctx Context, options ContinueAsNewOptions, wfn interface{}, args ...interface{}) error {
+	return internal.NewContinueAsNewErrorWithCarryOver(ctx, options, wfn, args...)
+}
+
 // NewTimeoutError creates TimeoutError instance.
 // Use NewHeartbeatTimeoutError to create heartbeat TimeoutError
 // WARNING: This function is public only to support unit testing of workflows.
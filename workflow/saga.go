@@ -0,0 +1,59 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package workflow
+
+import (
+	"go.uber.org/cadence/internal"
+)
+
+type (
+	// Saga records compensations as the steps of a workflow succeed and runs them when the workflow has to
+	// undo its work, for example:
+	//  saga := workflow.NewSaga(ctx, workflow.SagaOptions{})
+	//  defer func() {
+	//    if err != nil {
+	//      _ = saga.Compensate()
+	//    }
+	//  }()
+	//
+	//  if err = workflow.ExecuteActivity(ctx, ReserveInventory, order).Get(ctx, nil); err != nil {
+	//    return err
+	//  }
+	//  saga.AddCompensation(ReleaseInventory, order)
+	// Compensations run in a context created with NewDisconnectedContext, so they are executed even when the
+	// workflow is cancelled.
+	Saga = internal.Saga
+
+	// SagaOptions configure how a Saga runs its compensations.
+	// ParallelCompensation: optional, default false
+	//     Runs all compensations concurrently instead of one at a time in the reverse order they were added.
+	// ContinueWithError: optional, default false
+	//     Keeps running the remaining sequential compensations when one of them fails.
+	SagaOptions = internal.SagaOptions
+
+	// CompensationError is returned by Saga.Compensate when compensations fail.
+	CompensationError = internal.CompensationError
+)
+
+// NewSaga creates a Saga. Compensation activities are scheduled with the activity options of ctx.
+func NewSaga(ctx Context, options SagaOptions) *Saga {
+	return internal.NewSaga(ctx, options)
+}
@@ -21,6 +21,8 @@
 package workflow
 
 import (
+	"math/rand"
+
 	"github.com/uber-go/tally"
 	"go.uber.org/cadence/encoded"
 	"go.uber.org/cadence/internal"
@@ -41,14 +43,34 @@ type (
 	// Version represents a change version. See GetVersion call.
 	Version = internal.Version
 
+	// CancellationType specifies what happens when the context of an activity or child workflow is cancelled.
+	CancellationType = internal.CancellationType
+
 	// ChildWorkflowOptions stores all child workflow specific parameters that will be stored inside of a Context.
 	ChildWorkflowOptions = internal.ChildWorkflowOptions
 
+	// ContinueAsNewOptions stores the options of the run started by NewContinueAsNewErrorWithOptions.
+	ContinueAsNewOptions = internal.ContinueAsNewOptions
+
 	// RegisterOptions consists of options for registering a workflow
 	RegisterOptions = internal.RegisterWorkflowOptions
 
 	// Info information about currently executing workflow
 	Info = internal.WorkflowInfo
+
+	// UpdateHandlerOptions consists of options for registering an update handler. See SetUpdateHandler.
+	UpdateHandlerOptions = internal.UpdateHandlerOptions
+)
+
+const (
+	// CancellationTypeUnspecified keeps the behavior selected by the deprecated WaitForCancellation options.
+	CancellationTypeUnspecified CancellationType = internal.CancellationTypeUnspecified
+	// CancellationTypeTryCancel requests cancellation and fails the future with CanceledError immediately.
+	CancellationTypeTryCancel CancellationType = internal.CancellationTypeTryCancel
+	// CancellationTypeWaitCancellationCompleted requests cancellation and waits until it is handled.
+	CancellationTypeWaitCancellationCompleted CancellationType = internal.CancellationTypeWaitCancellationCompleted
+	// CancellationTypeAbandon fails the future with CanceledError without requesting cancellation.
+	CancellationTypeAbandon CancellationType = internal.CancellationTypeAbandon
 )
 
 // Deprecated: Global workflow registration methods are replaced by equivalent Worker instance methods.
@@ -217,6 +239,30 @@ func GetSignalChannel(ctx Context, signalName string) Channel {
 	return internal.GetSignalChannel(ctx, signalName)
 }
 
+// SetSignalHandler sets the handler invoked for every signal named signalName, as an alternative to draining
+// GetSignalChannel in a separate goroutine. The handler must be a function taking workflow.Context and optionally one
+// parameter, the signal payload is decoded into the type of that parameter. Handlers of all signals run one at a time,
+// in the order the signals were received, in a coroutine owned by the workflow, so a blocking handler delays the
+// handling of the following signals. Signals received before the handler is registered are delivered to it as well.
+// Once a handler is registered, signals with that name are no longer delivered to the signal channel. Calling
+// SetSignalHandler again for the same name replaces the handler. Handlers keep being invoked until the workflow
+// completes, including while it cleans up after a cancellation, so the context passed to them is not canceled
+// together with the workflow.
+// Example:
+//  err := workflow.SetSignalHandler(ctx, "add-item", func(ctx workflow.Context, item Item) {
+//    items = append(items, item)
+//  })
+func SetSignalHandler(ctx Context, signalName string, handler interface{}) error {
+	return internal.SetSignalHandler(ctx, signalName, handler)
+}
+
+// GetUnhandledSignalNames returns the sorted names of the signals received but not yet consumed, either buffered in
+// a signal channel or waiting for their signal handler. Signals still unhandled when the workflow completes or
+// continues as new are lost, so workflows can use it to drain them before calling NewContinueAsNewError.
+func GetUnhandledSignalNames(ctx Context) []string {
+	return internal.GetUnhandledSignalNames(ctx)
+}
+
 // SideEffect executes the provided function once, records its result into the workflow history. The recorded result on
 // history will be returned without executing the provided function during replay. This guarantees the deterministic
 // requirement for workflow as the exact same result will be returned in replay.
@@ -276,6 +322,22 @@ func MutableSideEffect(ctx Context, id string, f func(ctx Context) interface{},
 	return internal.MutableSideEffect(ctx, id, f, equals)
 }
 
+// NewRandom returns a pseudo random number generator that is safe to use in workflow code.
+// All generators of a workflow run are derived from a single seed that is recorded through SideEffect on the first
+// call, so they produce identical sequences on replay without recording a marker per draw. For example:
+//  r := workflow.NewRandom(ctx)
+//  choice := options[r.Intn(len(options))]
+// Each call returns a new generator with its own sequence.
+func NewRandom(ctx Context) *rand.Rand {
+	return internal.NewRandom(ctx)
+}
+
+// NewUUID returns a random (version 4) UUID string that is safe to use in workflow code.
+// It is derived from the same recorded seed as NewRandom and returns identical values on replay.
+func NewUUID(ctx Context) string {
+	return internal.NewUUID(ctx)
+}
+
 // DefaultVersion is a version returned by GetVersion for code that wasn't versioned before
 const DefaultVersion Version = internal.DefaultVersion
 
@@ -382,6 +444,40 @@ func SetQueryHandler(ctx Context, queryType string, handler interface{}) error {
 	return internal.SetQueryHandler(ctx, queryType, handler)
 }
 
+// SetUpdateHandler sets the handler of the updates named updateName. An update is sent by Client.UpdateWorkflow() and,
+// unlike a signal, lets the caller wait for the outcome of its handling, and unlike a query, may mutate the workflow
+// state. The handler must be a function taking workflow.Context and any number of serializable parameters and returning
+// either error or a serializable result and error. Each update is handled in its own coroutine, so the handler may call
+// blocking functions like workflow.ExecuteActivity(). The optional options.Validator is invoked with the same parameters
+// before the handler and rejects the update if it returns an error, the client then receives UpdateRejectedError.
+// Updates received while no handler is registered for their name are rejected, so you should call
+// workflow.SetUpdateHandler() at the beginning of the workflow code.
+// Updates are delivered as signals and their outcome is polled with a query, so they work with any cadence server.
+// Outcomes are kept in the workflow state until the run completes, updates still running when the workflow continues
+// as new are lost.
+// Example of workflow code that supports update "add":
+//  func MyWorkflow(ctx workflow.Context) (int, error) {
+//    total := 0
+//    err := workflow.SetUpdateHandler(ctx, "add", func(ctx workflow.Context, value int) (int, error) {
+//      total += value
+//      return total, nil
+//    }, workflow.UpdateHandlerOptions{
+//      Validator: func(ctx workflow.Context, value int) error {
+//        if value < 0 {
+//          return errors.New("value must not be negative")
+//        }
+//        return nil
+//      },
+//    })
+//    if err != nil {
+//      return 0, err
+//    }
+//    ...
+//  }
+func SetUpdateHandler(ctx Context, updateName string, handler interface{}, options UpdateHandlerOptions) error {
+	return internal.SetUpdateHandler(ctx, updateName, handler, options)
+}
+
 // IsReplaying returns whether the current workflow code is replaying.
 //
 // Warning! Never make decisions, like schedule activity/childWorkflow/timer or send/wait on future/channel, based on